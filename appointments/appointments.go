@@ -0,0 +1,279 @@
+// Package appointments reads the appointments exported to spreadsheets,
+// ODS or XLSX, and writes annotated copies of them.
+package appointments
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mbolis/mogo/model"
+	"github.com/mbolis/mogo/ods"
+	"github.com/xuri/excelize/v2"
+)
+
+// Appointment is a row of the sheet.
+type Appointment struct {
+	// Row is the index of the row in the sheet, from 0
+	Row int
+
+	// Date is the first instant of the day of the appointment and Time
+	// the time it starts, or the zero time if the sheet does not tell
+	Date time.Time
+	Time time.Time
+
+	Treatment string
+	Client    string
+
+	// Err tells why the row could not be read: the other fields are unset
+	Err error
+}
+
+// Book is the first sheet of a spreadsheet of appointments.
+type Book struct {
+	sheet  sheet
+	header int
+	width  int
+}
+
+// sheet is the first sheet of an ODS or XLSX file.
+type sheet interface {
+	rows() ([][]any, error)
+	setCell(r, c int, value string) error
+	write(w io.Writer) error
+}
+
+// Open reads the spreadsheet in filename, ODS or XLSX as told by its
+// extension.
+func Open(filename string) (*Book, error) {
+	switch ext := strings.ToLower(filepath.Ext(filename)); ext {
+	case ".ods":
+		data, err := os.ReadFile(filename)
+		if err != nil {
+			return nil, err
+		}
+		doc, err := ods.Open(bytes.NewReader(data), int64(len(data)))
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", filename, err)
+		}
+		if doc.Sheet(0) == nil {
+			return nil, fmt.Errorf("%s: no sheets", filename)
+		}
+		return &Book{sheet: &odsSheet{doc}}, nil
+
+	case ".xlsx":
+		f, err := excelize.OpenFile(filename)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", filename, err)
+		}
+		return &Book{sheet: &xlsxSheet{f, f.GetSheetName(0)}}, nil
+
+	default:
+		return nil, fmt.Errorf("unsupported file '%s', expected an .ods or .xlsx file", filename)
+	}
+}
+
+// Headers of the columns, in English and Italian, as lowercase.
+var (
+	dateHeaders      = []string{"date", "day", "data", "giorno"}
+	timeHeaders      = []string{"time", "hour", "start", "ora", "orario", "inizio"}
+	treatmentHeaders = []string{"treatment", "service", "trattamento", "servizio"}
+	clientHeaders    = []string{"client", "customer", "name", "cliente", "nome"}
+)
+
+// Appointments reads the rows after the header, the first row which is
+// not empty: its columns are found by name, of which only date and
+// treatment are required. Dates may be held by a column with times too.
+func (b *Book) Appointments(loc *time.Location) ([]Appointment, error) {
+	rows, err := b.sheet.rows()
+	if err != nil {
+		return nil, err
+	}
+
+	b.header = -1
+	for r, row := range rows {
+		b.width = max(b.width, len(row))
+		if b.header < 0 && len(row) > 0 {
+			b.header = r
+		}
+	}
+	if b.header < 0 {
+		return nil, errors.New("the sheet is empty")
+	}
+
+	header := rows[b.header]
+	dateCol := column(header, dateHeaders)
+	timeCol := column(header, timeHeaders)
+	treatmentCol := column(header, treatmentHeaders)
+	clientCol := column(header, clientHeaders)
+	if dateCol < 0 {
+		return nil, fmt.Errorf("no date column, expected one named: %s", strings.Join(dateHeaders, ", "))
+	}
+	if treatmentCol < 0 {
+		return nil, fmt.Errorf("no treatment column, expected one named: %s", strings.Join(treatmentHeaders, ", "))
+	}
+
+	var appointments []Appointment
+	for r := b.header + 1; r < len(rows); r++ {
+		row := rows[r]
+		if len(row) == 0 {
+			continue
+		}
+
+		a := Appointment{Row: r}
+		a.Date, a.Time, a.Err = dateTime(cell(row, dateCol), cell(row, timeCol), loc)
+		if a.Err == nil {
+			a.Treatment = text(cell(row, treatmentCol))
+			a.Client = text(cell(row, clientCol))
+		}
+		appointments = append(appointments, a)
+	}
+	return appointments, nil
+}
+
+// Annotate adds columns to the right of the sheet: headers are written on
+// the header row, and the annotations of each row, by its index.
+func (b *Book) Annotate(headers []string, annotations map[int][]string) error {
+	for i, h := range headers {
+		if err := b.sheet.setCell(b.header, b.width+i, h); err != nil {
+			return err
+		}
+	}
+	for r, values := range annotations {
+		for i, v := range values {
+			if err := b.sheet.setCell(r, b.width+i, v); err != nil {
+				return fmt.Errorf("row %d: %w", r+1, err)
+			}
+		}
+	}
+	return nil
+}
+
+// Write writes the spreadsheet, in its format.
+func (b *Book) Write(w io.Writer) error {
+	return b.sheet.write(w)
+}
+
+func column(header []any, names []string) int {
+	for c, v := range header {
+		name := strings.ToLower(strings.TrimSpace(text(v)))
+		for _, n := range names {
+			if name == n {
+				return c
+			}
+		}
+	}
+	return -1
+}
+
+func cell(row []any, c int) any {
+	if c < 0 || c >= len(row) {
+		return nil
+	}
+	return row[c]
+}
+
+func text(v any) string {
+	switch v := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return strings.TrimSpace(v)
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// Layouts of the dates and times held as text. Dates are day first, as
+// in Europe.
+var (
+	dateLayouts = []string{"2006-01-02", "2/1/2006", "2-1-2006", "2.1.2006", "2/1/06"}
+	timeLayouts = []string{"15:04", "15:04:05", "15.04"}
+)
+
+// dateTime reads the date, and time if any, of an appointment.
+func dateTime(dateValue, timeValue any, loc *time.Location) (date, t time.Time, err error) {
+	var wall time.Time
+	hasTime := false
+	switch v := dateValue.(type) {
+	case time.Time:
+		wall = v
+		hasTime = v.Hour() != 0 || v.Minute() != 0 || v.Second() != 0
+	case float64:
+		// spreadsheet serial dates, in days since 1899-12-30, whose
+		// fraction of day is rounded to the nearest second
+		wall, err = excelize.ExcelDateToTime(v, false)
+		wall = wall.Round(time.Second)
+		hasTime = v != float64(int(v))
+	case string:
+		wall, hasTime, err = parseText(v, dateLayouts, true)
+	case nil:
+		err = errors.New("no date")
+	default:
+		err = fmt.Errorf("invalid date '%v'", v)
+	}
+	if err != nil {
+		return
+	}
+
+	var clock time.Duration
+	switch v := timeValue.(type) {
+	case nil:
+	case time.Duration:
+		clock, hasTime = v, true
+	case time.Time:
+		clock, hasTime = v.Sub(model.DayStart(v)), true
+	case float64:
+		clock, hasTime = time.Duration((v-float64(int(v)))*24*float64(time.Hour)).Round(time.Second), true
+	case string:
+		var tm time.Time
+		if tm, _, err = parseText(v, timeLayouts, false); err != nil {
+			return
+		}
+		clock, hasTime = tm.Sub(model.DayStart(tm)), true
+	default:
+		err = fmt.Errorf("invalid time '%v'", v)
+		return
+	}
+	if timeValue == nil {
+		clock = wall.Sub(model.DayStart(wall))
+	}
+
+	y, m, d := wall.Date()
+	date = model.Midnight(y, m, d, loc)
+	if hasTime {
+		// on the wall clock, whatever the changes of the day
+		t = time.Date(y, m, d, 0, 0, 0, int(clock), loc)
+	}
+	return
+}
+
+// parseText parses a date, with a time if dates are parsed, or a time.
+func parseText(s string, layouts []string, dates bool) (t time.Time, hasTime bool, err error) {
+	s = strings.TrimSpace(s)
+	for _, layout := range layouts {
+		if t, err = time.Parse(layout, s); err == nil {
+			return t, false, nil
+		}
+		if !dates {
+			continue
+		}
+		for _, clock := range timeLayouts {
+			if t, err = time.Parse(layout+" "+clock, s); err == nil {
+				return t, true, nil
+			}
+		}
+	}
+	if dates {
+		return t, false, fmt.Errorf("invalid date '%s'", s)
+	}
+	return t, false, fmt.Errorf("invalid time '%s'", s)
+}
@@ -0,0 +1,162 @@
+package appointments
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/mbolis/mogo/ods"
+	"github.com/xuri/excelize/v2"
+)
+
+func TestAppointmentsODS(t *testing.T) {
+	doc, err := ods.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	sheet := doc.AddSheet("Appointments")
+	sheet.AddRow()
+	header := sheet.AddRow()
+	for c, h := range []string{"Data", "Ora", "Trattamento", "Cliente"} {
+		header.SetCellString(c, h)
+	}
+	row := sheet.AddRow()
+	row.SetCellDate(0, time.Date(2024, time.March, 14, 0, 0, 0, 0, time.UTC))
+	row.SetCellTime(1, time.Date(2024, time.March, 14, 9, 30, 0, 0, time.UTC))
+	row.SetCellString(2, "haircut")
+	row.SetCellString(3, "Anna")
+	row = sheet.AddRow()
+	row.SetCellString(0, "31/02/2024")
+	row.SetCellString(2, "waxing")
+
+	filename := filepath.Join(t.TempDir(), "appointments.ods")
+	f, err := os.Create(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := doc.Write(f); err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+
+	book, list := readBook(t, filename, 1, 2)
+	if a := list[1]; a.Err == nil || a.Err.Error() != "invalid date '31/02/2024'" {
+		t.Errorf("got error %v, want invalid date '31/02/2024'", a.Err)
+	}
+	if book.header != 1 || book.width != 4 {
+		t.Errorf("got header %d, width %d, want 1 and 4", book.header, book.width)
+	}
+	if err := book.Annotate([]string{"Verdict"}, map[int][]string{2: {"good"}}); err != nil {
+		t.Fatal(err)
+	}
+	writeBook(t, book, filename)
+
+	doc = openODS(t, filename)
+	if values, _ := doc.Sheet(0).Row(1).Values(); len(values) != 5 || values[4] != "Verdict" {
+		t.Errorf("got header %v, want the annotation header last", values)
+	}
+	if values, _ := doc.Sheet(0).Row(2).Values(); len(values) != 5 || values[4] != "good" {
+		t.Errorf("got row %v, want the annotation last", values)
+	}
+}
+
+func TestAppointmentsXLSX(t *testing.T) {
+	f := excelize.NewFile()
+	defer f.Close()
+	rows := [][]any{
+		{"Date", "Treatment", "Client"},
+		{"14/03/2024 9:30", "Haircut", "Anna"},
+		{"15/03/2024", "Waxing", nil},
+		// 2024-03-16 09:30, a few milliseconds early
+		{45367.395833, "Facial cleansing", nil},
+	}
+	for r, row := range rows {
+		cell, _ := excelize.CoordinatesToCellName(1, r+1)
+		if err := f.SetSheetRow("Sheet1", cell, &row); err != nil {
+			t.Fatal(err)
+		}
+	}
+	filename := filepath.Join(t.TempDir(), "appointments.xlsx")
+	if err := f.SaveAs(filename); err != nil {
+		t.Fatal(err)
+	}
+
+	book, list := readBook(t, filename, 0, 3)
+	if a := list[1]; a.Err != nil || a.Treatment != "Waxing" || !a.Time.IsZero() {
+		t.Errorf("got %+v, want a waxing without time", a)
+	}
+	// 09:30 in Rome
+	if a, want := list[2], time.Date(2024, time.March, 16, 8, 30, 0, 0, time.UTC); a.Err != nil || !a.Time.Equal(want) {
+		t.Errorf("got %+v, want a facial cleansing at %v", a, want)
+	}
+	if err := book.Annotate([]string{"Verdict"}, map[int][]string{1: {"good"}}); err != nil {
+		t.Fatal(err)
+	}
+	writeBook(t, book, filename)
+
+	f, err := excelize.OpenFile(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v, _ := f.GetCellValue("Sheet1", "D2"); v != "good" {
+		t.Errorf("got annotation '%s', want 'good'", v)
+	}
+}
+
+// readBook reads the n appointments of the sheets of the tests, after the
+// header row, checking the first one: a haircut at 9:30.
+func readBook(t *testing.T, filename string, header, n int) (*Book, []Appointment) {
+	rome, err := time.LoadLocation("Europe/Rome")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	book, err := Open(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	list, err := book.Appointments(rome)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(list) != n {
+		t.Fatalf("got %d appointments, want %d", len(list), n)
+	}
+
+	a := list[0]
+	if a.Row != header+1 || a.Treatment != "Haircut" && a.Treatment != "haircut" || a.Client != "Anna" {
+		t.Errorf("got %+v, want a haircut for Anna on row %d", a, header+1)
+	}
+	if want := time.Date(2024, time.March, 14, 9, 30, 0, 0, rome); !a.Time.Equal(want) {
+		t.Errorf("got time %v, want %v", a.Time, want)
+	}
+	if want := time.Date(2024, time.March, 14, 0, 0, 0, 0, rome); !a.Date.Equal(want) {
+		t.Errorf("got date %v, want %v", a.Date, want)
+	}
+	return book, list
+}
+
+func writeBook(t *testing.T, book *Book, filename string) {
+	f, err := os.Create(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	if err := book.Write(f); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func openODS(t *testing.T, filename string) *ods.Document {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	doc, err := ods.Open(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return doc
+}
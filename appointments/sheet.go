@@ -0,0 +1,75 @@
+package appointments
+
+import (
+	"errors"
+	"io"
+	"strconv"
+
+	"github.com/mbolis/mogo/ods"
+	"github.com/xuri/excelize/v2"
+)
+
+type odsSheet struct {
+	doc *ods.Document
+}
+
+func (s *odsSheet) rows() ([][]any, error) {
+	return s.doc.Sheet(0).Values()
+}
+
+func (s *odsSheet) setCell(r, c int, value string) error {
+	row := s.doc.Sheet(0).Row(r)
+	if row == nil {
+		return errors.New("no such row")
+	}
+	row.SetCellString(c, value)
+	return nil
+}
+
+func (s *odsSheet) write(w io.Writer) error {
+	return s.doc.Write(w)
+}
+
+type xlsxSheet struct {
+	f    *excelize.File
+	name string
+}
+
+// rows reads the raw values, so that dates and times are serial numbers.
+func (s *xlsxSheet) rows() ([][]any, error) {
+	cells, err := s.f.GetRows(s.name, excelize.Options{RawCellValue: true})
+	if err != nil {
+		return nil, err
+	}
+
+	rows := make([][]any, len(cells))
+	for r, row := range cells {
+		last := -1
+		values := make([]any, len(row))
+		for c, v := range row {
+			switch f, err := strconv.ParseFloat(v, 64); {
+			case v == "":
+				continue
+			case err == nil:
+				values[c] = f
+			default:
+				values[c] = v
+			}
+			last = c
+		}
+		rows[r] = values[:last+1]
+	}
+	return rows, nil
+}
+
+func (s *xlsxSheet) setCell(r, c int, value string) error {
+	cell, err := excelize.CoordinatesToCellName(c+1, r+1)
+	if err != nil {
+		return err
+	}
+	return s.f.SetCellStr(s.name, cell, value)
+}
+
+func (s *xlsxSheet) write(w io.Writer) error {
+	return s.f.Write(w)
+}
@@ -0,0 +1,82 @@
+// Package cache holds the caches shared by the ephemeris computations.
+package cache
+
+import (
+	"container/list"
+	"sync"
+)
+
+// LRU keeps the most recently used values, up to a fixed number.
+// It is safe for concurrent use.
+type LRU[K comparable, V any] struct {
+	mu      sync.Mutex
+	size    int
+	entries map[K]*list.Element
+	order   *list.List // front is most recent
+}
+
+type entry[K comparable, V any] struct {
+	key   K
+	value V
+}
+
+func New[K comparable, V any](size int) *LRU[K, V] {
+	if size <= 0 {
+		panic("cache size must be positive")
+	}
+	return &LRU[K, V]{
+		size:    size,
+		entries: make(map[K]*list.Element),
+		order:   list.New(),
+	}
+}
+
+func (c *LRU[K, V]) Get(key K) (value V, ok bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	e, ok := c.entries[key]
+	if !ok {
+		return value, false
+	}
+	c.order.MoveToFront(e)
+	return e.Value.(*entry[K, V]).value, true
+}
+
+// Put stores value, evicting the least recently used one if full.
+func (c *LRU[K, V]) Put(key K, value V) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if e, ok := c.entries[key]; ok {
+		e.Value.(*entry[K, V]).value = value
+		c.order.MoveToFront(e)
+		return
+	}
+
+	c.entries[key] = c.order.PushFront(&entry[K, V]{key, value})
+	if c.order.Len() > c.size {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.entries, oldest.Value.(*entry[K, V]).key)
+	}
+}
+
+// GetOrCompute returns the cached value of key, computing and storing it
+// if missing. The computation runs unlocked: goroutines missing the same
+// key at once may compute it more than once, which is harmless for pure
+// functions.
+func (c *LRU[K, V]) GetOrCompute(key K, compute func(K) V) V {
+	if value, ok := c.Get(key); ok {
+		return value
+	}
+	value := compute(key)
+	c.Put(key, value)
+	return value
+}
+
+func (c *LRU[K, V]) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.order.Len()
+}
@@ -0,0 +1,73 @@
+package cache
+
+import (
+	"sync"
+	"testing"
+)
+
+func TestLRUEviction(t *testing.T) {
+	c := New[int, string](2)
+	c.Put(1, "one")
+	c.Put(2, "two")
+	c.Get(1)
+	c.Put(3, "three")
+
+	if _, ok := c.Get(2); ok {
+		t.Error("2 was not evicted")
+	}
+	for _, k := range []int{1, 3} {
+		if _, ok := c.Get(k); !ok {
+			t.Errorf("%d was evicted", k)
+		}
+	}
+	if c.Len() != 2 {
+		t.Errorf("got %d values, want 2", c.Len())
+	}
+
+	c.Put(1, "uno")
+	if v, _ := c.Get(1); v != "uno" {
+		t.Errorf("got %q, want %q", v, "uno")
+	}
+}
+
+func TestGetOrCompute(t *testing.T) {
+	c := New[int, int](10)
+	calls := 0
+	square := func(k int) int {
+		calls++
+		return k * k
+	}
+
+	for i := 0; i < 3; i++ {
+		if v := c.GetOrCompute(4, square); v != 16 {
+			t.Errorf("got %d, want 16", v)
+		}
+	}
+	if calls != 1 {
+		t.Errorf("computed %d times, want 1", calls)
+	}
+}
+
+// TestConcurrent is meant to be run with the race detector.
+func TestConcurrent(t *testing.T) {
+	c := New[int, int](100)
+
+	var wg sync.WaitGroup
+	for g := 0; g < 8; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			for i := 0; i < 1000; i++ {
+				k := (i * (g + 1)) % 300
+				if v := c.GetOrCompute(k, func(k int) int { return -k }); v != -k {
+					t.Errorf("got %d for %d", v, k)
+				}
+			}
+		}(g)
+	}
+	wg.Wait()
+
+	if c.Len() > 100 {
+		t.Errorf("got %d values, more than the size", c.Len())
+	}
+}
@@ -0,0 +1,201 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/mbolis/mogo/config"
+	"github.com/mbolis/mogo/jd"
+	"github.com/mbolis/mogo/model"
+	"github.com/mbolis/mogo/observer"
+)
+
+// CellKind tells generators how a column value must be written, so that
+// spreadsheet applications can sort and compute on it.
+type CellKind int
+
+const (
+	TextCell     CellKind = iota // string
+	TimeCell                     // time.Time, only the time of day is kept
+	DateTimeCell                 // time.Time
+	IntegerCell                  // int
+	NumberCell                   // float64, shown with one decimal
+	PercentCell                  // float64, a fraction shown as a percentage
+)
+
+// Column is an optional column appended after the fixed ones.
+// Value returns nil when the cell must be left empty.
+type Column struct {
+	Header string
+	Kind   CellKind
+	Value  func(Row) any
+}
+
+func ExtraColumns(cfg config.Config) []Column {
+	var cols []Column
+	if cfg.UTCColumn {
+		cols = append(cols, Column{"UTC", DateTimeCell, func(r Row) any {
+			if r.Time.IsZero() {
+				return nil
+			}
+			return r.Time.UTC()
+		}})
+	}
+	if cfg.Location != nil {
+		for _, c := range []observer.Crossing{observer.Sunrise, observer.Sunset, observer.Moonrise, observer.Moonset} {
+			cols = append(cols, Column{c.String(), TimeCell, func(r Row) any { return r.Crossing(c) }})
+		}
+	}
+	if cfg.IlluminationColumn {
+		cols = append(cols, Column{"Illumination", PercentCell, func(r Row) any { return r.Illumination }})
+	}
+	if cfg.AgeColumn {
+		cols = append(cols, Column{"Age", NumberCell, func(r Row) any { return r.Age }})
+	}
+	if cfg.LunarDayColumn {
+		cols = append(cols,
+			Column{"Lunar day", IntegerCell, func(r Row) any { return r.LunarDay }},
+			Column{"Lunar day changes", TextCell, func(r Row) any { return r.LunarDayChanges() }},
+		)
+	}
+	if cfg.ElementColumn {
+		cols = append(cols,
+			Column{"Element", TextCell, func(r Row) any {
+				icon, name := r.ElementText()
+				return icon + " " + name
+			}},
+			Column{"Element change", TextCell, func(r Row) any { return r.ElementChange() }},
+		)
+	}
+	return cols
+}
+
+func (c Column) String(r Row) string {
+	switch v := c.Value(r).(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case time.Time:
+		switch c.Kind {
+		case TimeCell:
+			return clock(v, r.cfg.Precision.Layout())
+		default:
+			return date(r.cfg.Calendar, v) + " " + clock(v, r.cfg.Precision.Layout())
+		}
+	case float64:
+		switch c.Kind {
+		case PercentCell:
+			return fmt.Sprintf("%.0f%%", v*100)
+		default:
+			return fmt.Sprintf("%.1f", v)
+		}
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// Crossing returns the time of c on the day of the row, or nil if it
+// does not happen.
+func (r Row) Crossing(c observer.Crossing) any {
+	for _, e := range r.sky {
+		if e.Value == c {
+			return e.Time
+		}
+	}
+	return nil
+}
+
+// LunarDayChanges lists the times the lunar day changes on the day of
+// the row, as in "05:12 → 14".
+func (r Row) LunarDayChanges() string {
+	var changes []string
+	for _, c := range r.lunarDay.Changes {
+		changes = append(changes, fmt.Sprintf("%s → %d", clock(c.Time, r.cfg.Precision.Layout()), c.Value))
+	}
+	return strings.Join(changes, ", ")
+}
+
+// ElementChange lists the times the element changes on the day of the
+// row, as in "17:44 → 🍃 Leaf".
+func (r Row) ElementChange() string {
+	var changes []string
+	for _, c := range r.element.Events {
+		changes = append(changes, fmt.Sprintf("%s → %s %s", clock(c.Time, r.cfg.Precision.Layout()), r.cfg.Icons.Element(c.Value), T("organ."+c.Value.Organ())))
+	}
+	return strings.Join(changes, ", ")
+}
+
+// clock formats t with layout, followed by its UTC offset when it is not
+// the standard offset of the time zone, as in "14:05 +02:00".
+func clock(t time.Time, layout string) string {
+	if offset := offsetLabel(t); offset != "" {
+		return t.Format(layout) + " " + offset
+	}
+	return t.Format(layout)
+}
+
+// offsetLabel returns the UTC offset of t, as in "+02:00", if it is not the
+// standard offset of the time zone, or else "".
+func offsetLabel(t time.Time) string {
+	_, offset := t.Zone()
+	if offset == standardOffset(t) {
+		return ""
+	}
+	return t.Format("-07:00")
+}
+
+// standardOffset returns the offset of the time zone of t in the winter of
+// its year, taken as the smaller of the offsets in January and July: the
+// clocks go forward in summer on both hemispheres, and zones which never
+// change them, on a fixed offset or on local mean time, have only one.
+func standardOffset(t time.Time) int {
+	_, jan := time.Date(t.Year(), time.January, 1, 0, 0, 0, 0, t.Location()).Zone()
+	_, jul := time.Date(t.Year(), time.July, 1, 0, 0, 0, 0, t.Location()).Zone()
+	return min(jan, jul)
+}
+
+// date formats the date of t in the calendar, as in "1582-10-04".
+func date(c model.Calendar, t time.Time) string {
+	y, m, d := c.Date(t)
+	return fmt.Sprintf("%04d-%02d-%02d", y, m, d)
+}
+
+// CalendarName names the calendar, as in "Julian → Gregorian 1582-10-15".
+func CalendarName(c model.Calendar) string {
+	switch c {
+	case model.Gregorian:
+		return T("calendar.Gregorian")
+	case model.Julian:
+		return T("calendar.Julian")
+	default:
+		return fmt.Sprintf("%s → %s %s", T("calendar.Julian"), T("calendar.Gregorian"), c.Reform().Format(time.DateOnly))
+	}
+}
+
+// DateHeader translates the header of a date column, naming the calendar
+// when it is not the Gregorian one.
+func DateHeader(cfg config.Config, header string) string {
+	if cfg.Calendar == model.Gregorian {
+		return T(header)
+	}
+	return T(header) + " (" + CalendarName(cfg.Calendar) + ")"
+}
+
+// instant is the time of the row, or the start of its day if it has none.
+func (r Row) instant() time.Time {
+	if r.Time.IsZero() {
+		return r.Date
+	}
+	return r.Time
+}
+
+// DocumentNote describes the calendar of the dates and the time scale of
+// the event times, with the Delta-T in the middle of the calendar range.
+func DocumentNote(cfg config.Config) string {
+	start, end := cfg.Range()
+	mid := start.Add(end.Sub(start) / 2)
+	return fmt.Sprintf("%s: %s; %s: %s, ΔT = %.1f s",
+		T("Calendar"), CalendarName(cfg.Calendar), T("Time scale"), cfg.TimeScale, jd.DeltaT(mid).Seconds())
+}
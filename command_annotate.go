@@ -0,0 +1,116 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/mbolis/mogo/appointments"
+	"github.com/mbolis/mogo/config"
+	"github.com/mbolis/mogo/status"
+)
+
+var annotateCommand = command{
+	Name:    "annotate",
+	Summary: "annotate a spreadsheet of appointments with the moon and the verdict of each one",
+	Args:    "FILENAME",
+	Options: `    -o FILENAME
+    --output FILENAME
+        path to the annotated copy (default: FILENAME with '-annotated' before the extension)
+        the first sheet of FILENAME, .ods or .xlsx, holds a header row naming the columns:
+        date and treatment (required), time and client; dates are read day first, as 14/03/2024
+`,
+	Groups: config.SettingsFlags | config.VerdictFlags | config.LocationFlags,
+	Setup: func(fs *config.FlagSet) func(config.Config, []string) {
+		var output string
+		fs.StringVar(&output, "o", "", "")
+		fs.StringVar(&output, "output", "", "")
+		return func(cfg config.Config, args []string) {
+			if len(args) != 1 {
+				fmt.Fprintln(os.Stderr, "usage: mogo annotate [options] FILENAME")
+				os.Exit(2)
+			}
+			runAnnotate(cfg, args[0], output)
+		}
+	},
+}
+
+func runAnnotate(cfg config.Config, filename, output string) {
+	book, err := appointments.Open(filename)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	list, err := book.Appointments(cfg.TZ)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%s: %v\n", filename, err)
+		os.Exit(1)
+	}
+
+	annotations := make(map[int][]string)
+	for _, a := range list {
+		annotations[a.Row] = Annotate(cfg, a)
+	}
+	err = book.Annotate([]string{T("Phase"), T("Sign"), T("Verdict")}, annotations)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%s: %v\n", filename, err)
+		os.Exit(1)
+	}
+
+	if output == "" {
+		ext := filepath.Ext(filename)
+		output = strings.TrimSuffix(filename, ext) + "-annotated" + ext
+	}
+	out, err := os.Create(output)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	err = book.Write(out)
+	if closeErr := out.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%s: %v\n", output, err)
+		os.Exit(1)
+	}
+	fmt.Fprintf(os.Stderr, "%d appointments annotated in %s\n", len(list), output)
+}
+
+// Annotate returns the phase, the sign and the verdict for the treatment
+// of an appointment, at its time or else at the start of its day.
+func Annotate(cfg config.Config, a appointments.Appointment) []string {
+	if a.Err != nil {
+		return []string{"", "", a.Err.Error()}
+	}
+
+	t := a.Time
+	if t.IsZero() {
+		t = a.Date
+	}
+	r := Row{Entry: EntryAt(cfg, t), cfg: cfg}
+	r.Time = a.Time
+
+	_, phaseName := r.PhaseText()
+	_, signName := r.SignText()
+	treatment, ok := parseTreatment(a.Treatment)
+	if !ok {
+		return []string{phaseName, signName, T("Unknown treatment")}
+	}
+	return []string{phaseName, signName, cfg.Icons.Status(r.Verdict(treatment))}
+}
+
+// parseTreatment reads a treatment by its key, its name or its name in
+// the language of the output.
+func parseTreatment(s string) (status.Treatment, bool) {
+	if t, err := status.ParseTreatment(s); err == nil {
+		return t, true
+	}
+	for _, t := range status.Treatments {
+		if strings.EqualFold(s, T(t.String())) {
+			return t, true
+		}
+	}
+	return -1, false
+}
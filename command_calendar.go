@@ -0,0 +1,113 @@
+package main
+
+import (
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+
+	"github.com/mbolis/mogo/config"
+	"golang.org/x/term"
+)
+
+var calendarCommand = command{
+	Name:    "calendar",
+	Summary: "write the calendar of moon phases, signs and verdicts for a year or month",
+	Groups:  config.AllFlags,
+	Setup: func(*config.FlagSet) func(config.Config, []string) {
+		return runCalendar
+	},
+}
+
+func runCalendar(cfg config.Config, _ []string) {
+	if cfg.TUI {
+		RunTUI(cfg)
+		return
+	}
+
+	start, end := cfg.Range()
+
+	var out io.WriteCloser
+	if cfg.Output == "-" {
+		out = os.Stdout
+	} else {
+		var err error
+		out, err = os.Create(cfg.Output)
+		if err != nil {
+			panic(err)
+		}
+		defer out.Close()
+	}
+
+	switch {
+	case cfg.Format() == config.CSV && cfg.Output == "-" && term.IsTerminal(int(os.Stdout.Fd())):
+		_, noColor := os.LookupEnv("NO_COLOR")
+		GenerateTerminal(cfg, CollectDays(cfg, start, end), out, !noColor)
+	case cfg.Format() == config.CSV:
+		StreamCSV(cfg, StreamDays(cfg, start, end), out)
+	default:
+		Generate(cfg, CollectDays(cfg, start, end), out)
+	}
+}
+
+// Generate writes the calendar of days in the configured format.
+func Generate(cfg config.Config, days []Day, out io.Writer) {
+	switch cfg.Format() {
+	case config.CSV:
+		GenerateCSV(cfg, days, out)
+	case config.XLSX:
+		GenerateXLSX(cfg, days, out)
+	case config.ODS:
+		GenerateODS(cfg, days, out)
+	case config.Markdown:
+		GenerateMarkdown(cfg, days, out)
+	case config.HTML:
+		GenerateHTML(cfg, days, out)
+	case config.PDF:
+		GeneratePDF(cfg, days, out)
+	}
+}
+
+// GeneratePDF converts the ODS calendar with LibreOffice.
+func GeneratePDF(cfg config.Config, days []Day, out io.Writer) {
+	tmp, err := os.MkdirTemp("", "mogo-")
+	if err != nil {
+		panic(err)
+	}
+	defer os.RemoveAll(tmp)
+
+	odsOut, err := os.Create(filepath.Join(tmp, "out.ods"))
+	if err != nil {
+		panic(err)
+	}
+	defer odsOut.Close()
+
+	GenerateODS(cfg, days, odsOut)
+	soffice, err := exec.LookPath("soffice")
+	if err == nil {
+		soffice, err = filepath.Abs(soffice)
+	}
+	if err != nil {
+		panic(err)
+	}
+
+	cmd := exec.Command(soffice, "--headless",
+		"-env:UserInstallation=file:///tmp/LibreOffice_Conversion_mogo",
+		"--convert-to", "pdf:calc_pdf_Export", "--outdir", tmp, odsOut.Name())
+	cmd.Stdout = os.Stderr
+	cmd.Stderr = os.Stderr
+	if err = cmd.Run(); err != nil {
+		panic(err)
+	}
+
+	pdf, err := os.Open(filepath.Join(tmp, "out.pdf"))
+	if err != nil {
+		panic(err)
+	}
+	defer pdf.Close()
+
+	_, err = io.Copy(out, pdf)
+	if err != nil {
+		panic(err)
+	}
+}
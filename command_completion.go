@@ -0,0 +1,295 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/mbolis/mogo/config"
+)
+
+var completionCommand = command{
+	Name:        "completion",
+	Summary:     "print the shell completion script for bash, zsh or fish",
+	Args:        "bash|zsh|fish",
+	Subcommands: []string{"bash", "zsh", "fish"},
+	Setup: func(*config.FlagSet) func(config.Config, []string) {
+		return runCompletion
+	},
+}
+
+// flagValues lists the values completed after an option; nil means file names.
+var flagValues = map[string][]string{
+	"m":              {"jan", "feb", "mar", "apr", "may", "jun", "jul", "aug", "sep", "oct", "nov", "dec"},
+	"month":          {"jan", "feb", "mar", "apr", "may", "jun", "jul", "aug", "sep", "oct", "nov", "dec"},
+	"f":              {"csv", "xlsx", "ods", "pdf", "md", "html"},
+	"format":         {"csv", "xlsx", "ods", "pdf", "md", "html"},
+	"i":              {"arrows", "thumbs", "semaphore", "ascii"},
+	"icon":           {"arrows", "thumbs", "semaphore", "ascii"},
+	"icons":          {"arrows", "thumbs", "semaphore", "ascii"},
+	"l":              {"en", "it"},
+	"lang":           {"en", "it"},
+	"o":              nil,
+	"out":            nil,
+	"output":         nil,
+	"config":         nil,
+	"icons-file":     nil,
+	"rules":          nil,
+	"lunar-day":      {"tithi", "moonrise"},
+	"calendar":       {"gregorian", "julian", "reform"},
+	"zodiac":         {"tropical", "constellations", "iau"},
+	"element-zodiac": {"tropical", "constellations", "iau"},
+	"precision":      {"minute", "second"},
+	"rounding":       {"nearest", "floor", "ceil"},
+	"time-scale":     {"ut", "tt"},
+}
+
+func runCompletion(_ config.Config, args []string) {
+	if len(args) != 1 {
+		fmt.Fprintln(os.Stderr, "usage: mogo completion bash|zsh|fish")
+		os.Exit(2)
+	}
+
+	switch args[0] {
+	case "bash":
+		completeBash(os.Stdout)
+	case "zsh":
+		completeZsh(os.Stdout)
+	case "fish":
+		completeFish(os.Stdout)
+	default:
+		fmt.Fprintf(os.Stderr, "unsupported shell '%s', expected one of: bash, zsh, fish\n", args[0])
+		os.Exit(2)
+	}
+}
+
+// flags returns the options of cmd, as typed on the command line.
+func (cmd command) flags() (flags []*flag.Flag) {
+	fs, _ := cmd.FlagSet()
+	fs.VisitAll(func(f *flag.Flag) { flags = append(flags, f) })
+	return
+}
+
+func dashed(name string) string {
+	if len(name) == 1 {
+		return "-" + name
+	}
+	return "--" + name
+}
+
+func isBoolFlag(f *flag.Flag) bool {
+	b, ok := f.Value.(interface{ IsBoolFlag() bool })
+	return ok && b.IsBoolFlag()
+}
+
+func commandNames() []string {
+	var names []string
+	for _, cmd := range commands {
+		names = append(names, cmd.Name)
+	}
+	return names
+}
+
+// valueFlags groups the options by the values completed after them.
+func valueFlags() (files []string, values []flagGroup) {
+	var names []string
+	for name := range flagValues {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		vs := flagValues[name]
+		if vs == nil {
+			files = append(files, dashed(name))
+			continue
+		}
+
+		key := strings.Join(vs, " ")
+		i := slices.IndexFunc(values, func(g flagGroup) bool { return g.values == key })
+		if i < 0 {
+			i = len(values)
+			values = append(values, flagGroup{values: key})
+		}
+		values[i].names = append(values[i].names, dashed(name))
+	}
+	return
+}
+
+type flagGroup struct {
+	names  []string
+	values string
+}
+
+func completeBash(w io.Writer) {
+	files, values := valueFlags()
+
+	fmt.Fprint(w, `# bash completion for mogo
+_mogo() {
+	local cur=${COMP_WORDS[COMP_CWORD]} prev=${COMP_WORDS[COMP_CWORD-1]}
+	local cmd=calendar
+	if [[ $COMP_CWORD -gt 1 && ${COMP_WORDS[1]} != -* ]]; then
+		cmd=${COMP_WORDS[1]}
+	fi
+
+	case $prev in
+`)
+	for _, g := range values {
+		fmt.Fprintf(w, "\t%s)\n\t\tCOMPREPLY=($(compgen -W %q -- \"$cur\"))\n\t\treturn ;;\n", strings.Join(g.names, "|"), g.values)
+	}
+	fmt.Fprintf(w, "\t%s)\n\t\tCOMPREPLY=($(compgen -f -- \"$cur\"))\n\t\treturn ;;\n", strings.Join(files, "|"))
+	fmt.Fprintf(w, `	esac
+
+	if [[ $COMP_CWORD -eq 1 && $cur != -* ]]; then
+		COMPREPLY=($(compgen -W %q -- "$cur"))
+		return
+	fi
+
+	local opts subcommands
+	case $cmd in
+`, strings.Join(commandNames(), " "))
+	for _, cmd := range commands {
+		var opts []string
+		for _, f := range cmd.flags() {
+			opts = append(opts, dashed(f.Name))
+		}
+		opts = append(opts, "--help")
+		fmt.Fprintf(w, "\t%s)\n\t\topts=%q\n", cmd.Name, strings.Join(opts, " "))
+		if len(cmd.Subcommands) > 0 {
+			fmt.Fprintf(w, "\t\tsubcommands=%q\n", strings.Join(cmd.Subcommands, " "))
+		}
+		fmt.Fprint(w, "\t\t;;\n")
+	}
+	fmt.Fprint(w, `	esac
+
+	if [[ $cur == -* ]]; then
+		COMPREPLY=($(compgen -W "$opts" -- "$cur"))
+	elif [[ -n $subcommands && $COMP_CWORD -eq 2 ]]; then
+		COMPREPLY=($(compgen -W "$subcommands" -- "$cur"))
+	else
+		COMPREPLY=($(compgen -f -- "$cur"))
+	fi
+}
+complete -F _mogo mogo
+`)
+}
+
+func completeZsh(w io.Writer) {
+	files, values := valueFlags()
+
+	fmt.Fprint(w, `#compdef mogo
+
+_mogo() {
+	local cmd=calendar
+	if (( CURRENT > 2 )) && [[ $words[2] != -* ]]; then
+		cmd=$words[2]
+	fi
+
+	case $words[CURRENT-1] in
+`)
+	for _, g := range values {
+		fmt.Fprintf(w, "\t%s)\n\t\tcompadd -- %s\n\t\treturn ;;\n", strings.Join(g.names, "|"), g.values)
+	}
+	fmt.Fprintf(w, "\t%s)\n\t\t_files\n\t\treturn ;;\n", strings.Join(files, "|"))
+	fmt.Fprint(w, `	esac
+
+	if (( CURRENT == 2 )) && [[ $words[CURRENT] != -* ]]; then
+		local -a commands=(
+`)
+	for _, cmd := range commands {
+		fmt.Fprintf(w, "\t\t\t%s\n", zshQuote(cmd.Name+":"+strings.ReplaceAll(cmd.Summary, ":", `\:`)))
+	}
+	fmt.Fprint(w, `		)
+		_describe command commands
+		return
+	fi
+
+	local -a opts subcommands
+	case $cmd in
+`)
+	for _, cmd := range commands {
+		var opts []string
+		for _, f := range cmd.flags() {
+			opts = append(opts, dashed(f.Name))
+		}
+		opts = append(opts, "--help")
+		fmt.Fprintf(w, "\t%s)\n\t\topts=(%s)\n", cmd.Name, strings.Join(opts, " "))
+		if len(cmd.Subcommands) > 0 {
+			fmt.Fprintf(w, "\t\tsubcommands=(%s)\n", strings.Join(cmd.Subcommands, " "))
+		}
+		fmt.Fprint(w, "\t\t;;\n")
+	}
+	fmt.Fprint(w, `	esac
+
+	if [[ $words[CURRENT] == -* ]]; then
+		compadd -- $opts
+	elif (( $#subcommands && CURRENT == 3 )); then
+		compadd -- $subcommands
+	else
+		_files
+	fi
+}
+
+if [[ $zsh_eval_context[-1] == loadautofunc ]]; then
+	_mogo "$@"
+else
+	compdef _mogo mogo
+fi
+`)
+}
+
+func zshQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+func completeFish(w io.Writer) {
+	var others []string
+	for _, name := range commandNames() {
+		if name != calendarCommand.Name {
+			others = append(others, name)
+		}
+	}
+
+	fmt.Fprint(w, "# fish completion for mogo\ncomplete -c mogo -f\n\n")
+	for _, cmd := range commands {
+		fmt.Fprintf(w, "complete -c mogo -n __fish_use_subcommand -a %s -d %s\n", cmd.Name, zshQuote(cmd.Summary))
+	}
+
+	for _, cmd := range commands {
+		fmt.Fprintln(w)
+
+		cond := "__fish_seen_subcommand_from " + cmd.Name
+		if cmd.Name == calendarCommand.Name {
+			// calendar is the default command
+			cond = "not __fish_seen_subcommand_from " + strings.Join(others, " ")
+		}
+
+		for _, sub := range cmd.Subcommands {
+			fmt.Fprintf(w, "complete -c mogo -n '%s; and not __fish_seen_subcommand_from %s' -a %s\n",
+				cond, strings.Join(cmd.Subcommands, " "), sub)
+		}
+
+		for _, f := range cmd.flags() {
+			opt := "-l " + f.Name
+			if len(f.Name) == 1 {
+				opt = "-s " + f.Name
+			}
+
+			values, ok := flagValues[f.Name]
+			switch {
+			case isBoolFlag(f):
+			case ok && values == nil:
+				opt += " -r -F"
+			case ok:
+				opt += fmt.Sprintf(" -r -a '%s'", strings.Join(values, " "))
+			default:
+				opt += " -r"
+			}
+			fmt.Fprintf(w, "complete -c mogo -n '%s' %s\n", cond, opt)
+		}
+	}
+}
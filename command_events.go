@@ -0,0 +1,124 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mbolis/mogo/config"
+	"github.com/mbolis/mogo/phase"
+)
+
+var eventsCommand = command{
+	Name:    "events",
+	Summary: "list the new and full moons, the sign ingresses of the moon and, given a place, its rising and setting",
+	Options: `    --json
+        write one JSON object per line instead of CSV
+    --lunar-days
+        list the changes of lunar day too
+`,
+	Groups: config.SettingsFlags | config.PeriodFlags | config.OutputFlags | config.LocationFlags,
+	Setup: func(fs *config.FlagSet) func(config.Config, []string) {
+		var asJSON, lunarDays bool
+		fs.BoolVar(&asJSON, "json", false, "")
+		fs.BoolVar(&lunarDays, "lunar-days", false, "")
+		return func(cfg config.Config, _ []string) {
+			runEvents(cfg, asJSON, lunarDays)
+		}
+	},
+}
+
+// Event is a change of moon phase or sign.
+type Event struct {
+	Time         time.Time `json:"time"`
+	Type         string    `json:"type"`
+	Value        string    `json:"value"`
+	Name         string    `json:"name"`
+	Illumination float64   `json:"illumination"`
+	Age          float64   `json:"age"`
+}
+
+// Events lists the events of days in chronological order.
+func Events(days []Day, lunarDays bool) []Event {
+	var events []Event
+	for _, d := range days {
+		var dayEvents []Event
+		for _, e := range d.Phase.Events {
+			dayEvents = append(dayEvents, newEvent(e.Time, "phase", e.Value.String(), T("phase."+e.Value.String())))
+		}
+		for _, e := range d.Sign.Events {
+			dayEvents = append(dayEvents, newEvent(e.Time, "sign", e.Value.String(), T("zodiac."+e.Value.String())))
+		}
+		for _, e := range d.Sky {
+			dayEvents = append(dayEvents, newEvent(e.Time, "horizon", e.Value.String(), T(e.Value.String())))
+		}
+		if lunarDays {
+			for _, e := range d.LunarDay.Changes {
+				dayEvents = append(dayEvents, newEvent(e.Time, "lunar-day", strconv.Itoa(e.Value), strconv.Itoa(e.Value)))
+			}
+		}
+		sort.SliceStable(dayEvents, func(i, j int) bool { return dayEvents[i].Time.Before(dayEvents[j].Time) })
+		events = append(events, dayEvents...)
+	}
+	return events
+}
+
+func newEvent(t time.Time, typ, value, name string) Event {
+	return Event{Time: t, Type: typ, Value: strings.ToLower(value), Name: name}
+}
+
+// WithMoon adds the illumination and the age of the Moon at the time of
+// the event, which only JSON shows.
+func (e Event) WithMoon() Event {
+	ph := phase.CalcTime(e.Time)
+	e.Illumination, e.Age = ph.Illumination, ph.Age()
+	return e
+}
+
+func runEvents(cfg config.Config, asJSON, lunarDays bool) {
+	start, end := cfg.Range()
+	days := StreamDays(cfg, start, end)
+
+	var out io.WriteCloser
+	if cfg.Output == "-" {
+		out = os.Stdout
+	} else {
+		var err error
+		out, err = os.Create(cfg.Output)
+		if err != nil {
+			panic(err)
+		}
+		defer out.Close()
+	}
+
+	if asJSON {
+		enc := json.NewEncoder(out)
+		for d := range days {
+			for _, e := range Events([]Day{d}, lunarDays) {
+				if err := enc.Encode(e.WithMoon()); err != nil {
+					panic(err)
+				}
+			}
+		}
+		return
+	}
+
+	types := map[string]string{"phase": T("Phase"), "sign": T("Sign"), "horizon": T("Horizon"), "lunar-day": T("Lunar day")}
+
+	w := csv.NewWriter(out)
+	rows := [][]string{{T("Time"), T("Event"), T("Value")}}
+	for d := range days {
+		for _, e := range Events([]Day{d}, lunarDays) {
+			rows = append(rows, []string{e.Time.Format(time.RFC3339), types[e.Type], e.Name})
+		}
+		if err := w.WriteAll(rows); err != nil {
+			panic(err)
+		}
+		rows = rows[:0]
+	}
+}
@@ -0,0 +1,233 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/mbolis/mogo/config"
+	"github.com/mbolis/mogo/jd"
+	"github.com/mbolis/mogo/lunarday"
+	"github.com/mbolis/mogo/model"
+	"github.com/mbolis/mogo/observer"
+	"github.com/mbolis/mogo/phase"
+	"github.com/mbolis/mogo/position"
+	"github.com/mbolis/mogo/sign"
+	"github.com/mbolis/mogo/status"
+	"github.com/mshafiee/swephgo"
+)
+
+var nowCommand = command{
+	Name:    "now",
+	Summary: "show the current moon phase and sign, with the verdicts for the treatments",
+	Options: `    --json
+        write a JSON object, for widgets and scripts
+`,
+	Groups: config.SettingsFlags | config.VerdictFlags | config.LocationFlags,
+	Setup: func(fs *config.FlagSet) func(config.Config, []string) {
+		var asJSON bool
+		fs.BoolVar(&asJSON, "json", false, "")
+		return func(cfg config.Config, _ []string) {
+			runNow(cfg, asJSON)
+		}
+	},
+}
+
+// Instant is the state of the Moon at a given time.
+type Instant struct {
+	Row
+	NextPhase *model.Event[phase.Phase]
+	NextSign  *model.Event[sign.Sign]
+
+	// NextCrossings are the next moonrise and moonset, if a place is given
+	NextCrossings []model.Event[observer.Crossing]
+}
+
+func Now(cfg config.Config) Instant {
+	return InstantAt(cfg, time.Now().In(cfg.TZ))
+}
+
+// EntryAt returns the state of the Moon at t.
+func EntryAt(cfg config.Config, t time.Time) status.Entry {
+	ph := phase.CalcTime(t)
+	moon := position.CalcTime(t, swephgo.SeMoon)
+	return status.Entry{
+		Date:         model.DayStart(t),
+		Time:         t,
+		Phase:        ph.Phase(),
+		Sign:         cfg.Zodiac.Of(moon.Longitude, moon.JD),
+		Illumination: ph.Illumination,
+		Age:          ph.Age(),
+		LunarDay:     lunarday.ForDay(t, cfg.LunarDay, cfg.Location).At(t),
+	}
+}
+
+func InstantAt(cfg config.Config, t time.Time) Instant {
+	i := Instant{
+		Row:       Row{Entry: EntryAt(cfg, t), cfg: cfg},
+		NextPhase: nextEvent(cfg, t, phase.Events),
+		NextSign: nextEvent(cfg, t, func(start, end time.Time) []model.Event[sign.Sign] {
+			return sign.Events(start, end, cfg.Zodiac)
+		}),
+	}
+
+	if cfg.Location != nil {
+		for _, c := range []observer.Crossing{observer.Moonrise, observer.Moonset} {
+			if next, ok := observer.Next(c, t, *cfg.Location); ok {
+				e := model.Event[observer.Crossing]{Time: next.In(t.Location()), Value: c}
+				i.NextCrossings = append(i.NextCrossings, e.Round(cfg.Precision, cfg.Rounding))
+			}
+		}
+		sort.Slice(i.NextCrossings, func(a, b int) bool { return i.NextCrossings[a].Time.Before(i.NextCrossings[b].Time) })
+	}
+	return i
+}
+
+// nextEvent finds the first event after t, searching them over the lunar
+// month ahead at once, to the precision of cfg.
+func nextEvent[T ~int](cfg config.Config, t time.Time, events func(start, end time.Time) []model.Event[T]) *model.Event[T] {
+	for _, e := range events(t, t.AddDate(0, 0, 32)) {
+		if e.Time.After(t) {
+			e = e.Round(cfg.Precision, cfg.Rounding)
+			return &e
+		}
+	}
+	return nil
+}
+
+func runNow(cfg config.Config, asJSON bool) {
+	now := Now(cfg)
+	if asJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(now.JSON()); err != nil {
+			panic(err)
+		}
+		return
+	}
+
+	phaseIcon, phaseName := now.PhaseText()
+	signIcon, signName := now.SignText()
+	fmt.Printf("%s\n", clock(now.Time, "2006-01-02 "+cfg.Precision.Layout()))
+	fmt.Printf("  %s %s (%.0f%%, %.1f %s)\n", phaseIcon, phaseName, now.Illumination*100, now.Age, T("days"))
+	fmt.Printf("  %s %s\n", signIcon, signName)
+	fmt.Printf("  %s %d\n", T("Lunar day"), now.LunarDay)
+
+	if e := now.NextPhase; e != nil {
+		fmt.Printf("  %s %s %s %s (%s)\n", cfg.Icons.Phase(e.Value), T("phase."+e.Value.String()), T("in"),
+			formatDuration(e.Time.Sub(now.Time)), clock(e.Time, "2006-01-02 "+cfg.Precision.Layout()))
+	}
+	if e := now.NextSign; e != nil {
+		fmt.Printf("  %s %s %s %s (%s)\n", cfg.Icons.Sign(e.Value), T("zodiac."+e.Value.String()), T("in"),
+			formatDuration(e.Time.Sub(now.Time)), clock(e.Time, "2006-01-02 "+cfg.Precision.Layout()))
+	}
+
+	for _, e := range now.NextCrossings {
+		fmt.Printf("  %s %s %s (%s)\n", T(e.Value.String()), T("in"),
+			formatDuration(e.Time.Sub(now.Time)), clock(e.Time, "2006-01-02 "+cfg.Precision.Layout()))
+	}
+
+	fmt.Println()
+	for _, t := range status.Treatments {
+		s := now.Verdict(t)
+		fmt.Printf("  %s %s\n", padRight(cfg.Icons.Status(s), 4), T(t.String()))
+	}
+}
+
+// formatDuration rounds d to minutes, as in "2d 3h 15m".
+func formatDuration(d time.Duration) string {
+	d = d.Round(time.Minute)
+	days := d / (24 * time.Hour)
+	hours := d % (24 * time.Hour) / time.Hour
+	minutes := d % time.Hour / time.Minute
+
+	var parts []string
+	if days > 0 {
+		parts = append(parts, fmt.Sprintf("%dd", days))
+	}
+	if days > 0 || hours > 0 {
+		parts = append(parts, fmt.Sprintf("%dh", hours))
+	}
+	parts = append(parts, fmt.Sprintf("%dm", minutes))
+	return strings.Join(parts, " ")
+}
+
+type instantJSON struct {
+	Time         time.Time              `json:"time"`
+	TimeScale    string                 `json:"time_scale"`
+	DeltaT       float64                `json:"delta_t"`
+	Phase        valueJSON              `json:"phase"`
+	Illumination float64                `json:"illumination"`
+	Age          float64                `json:"age"`
+	LunarDay     int                    `json:"lunar_day"`
+	Sign         valueJSON              `json:"sign"`
+	NextPhase    *eventJSON             `json:"next_phase,omitempty"`
+	NextSign     *eventJSON             `json:"next_sign,omitempty"`
+	NextMoonrise *time.Time             `json:"next_moonrise,omitempty"`
+	NextMoonset  *time.Time             `json:"next_moonset,omitempty"`
+	Verdicts     map[string]verdictJSON `json:"verdicts"`
+}
+
+type valueJSON struct {
+	Value string `json:"value"`
+	Name  string `json:"name"`
+	Icon  string `json:"icon"`
+}
+
+type eventJSON struct {
+	valueJSON
+	Time    time.Time `json:"time"`
+	Seconds int64     `json:"seconds"`
+}
+
+type verdictJSON struct {
+	Status status.Status `json:"status"`
+	Icon   string        `json:"icon"`
+}
+
+func (i Instant) JSON() instantJSON {
+	phaseIcon, phaseName := i.PhaseText()
+	signIcon, signName := i.SignText()
+	v := instantJSON{
+		Time:         i.Time,
+		TimeScale:    i.cfg.TimeScale.String(),
+		DeltaT:       jd.DeltaT(i.Time).Seconds(),
+		Phase:        valueJSON{strings.ToLower(i.Phase.String()), phaseName, phaseIcon},
+		Illumination: i.Illumination,
+		Age:          i.Age,
+		LunarDay:     i.LunarDay,
+		Sign:         valueJSON{strings.ToLower(i.Sign.String()), signName, signIcon},
+		Verdicts:     make(map[string]verdictJSON),
+	}
+
+	if e := i.NextPhase; e != nil {
+		v.NextPhase = &eventJSON{
+			valueJSON{strings.ToLower(e.Value.String()), T("phase." + e.Value.String()), i.cfg.Icons.Phase(e.Value)},
+			e.Time, int64(e.Time.Sub(i.Time).Seconds()),
+		}
+	}
+	if e := i.NextSign; e != nil {
+		v.NextSign = &eventJSON{
+			valueJSON{strings.ToLower(e.Value.String()), T("zodiac." + e.Value.String()), i.cfg.Icons.Sign(e.Value)},
+			e.Time, int64(e.Time.Sub(i.Time).Seconds()),
+		}
+	}
+
+	for _, e := range i.NextCrossings {
+		switch e.Value {
+		case observer.Moonrise:
+			v.NextMoonrise = &e.Time
+		case observer.Moonset:
+			v.NextMoonset = &e.Time
+		}
+	}
+
+	for _, t := range status.Treatments {
+		s := i.Verdict(t)
+		v.Verdicts[t.Key()] = verdictJSON{s, i.cfg.Icons.Status(s)}
+	}
+	return v
+}
@@ -0,0 +1,50 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/mbolis/mogo/config"
+	"github.com/mbolis/mogo/rules"
+)
+
+var rulesCommand = command{
+	Name:        "rules",
+	Summary:     "check rules files overriding the verdicts of the treatments",
+	Args:        "validate [FILENAME...]",
+	Groups:      config.SettingsFlags,
+	Subcommands: []string{"validate"},
+	Setup: func(*config.FlagSet) func(config.Config, []string) {
+		return runRules
+	},
+}
+
+func runRules(cfg config.Config, args []string) {
+	if len(args) == 0 || args[0] != "validate" {
+		fmt.Fprintln(os.Stderr, "usage: mogo rules validate [FILENAME...]")
+		os.Exit(2)
+	}
+
+	filenames := args[1:]
+	if len(filenames) == 0 && cfg.RulesFile != "" {
+		filenames = []string{cfg.RulesFile}
+	}
+	if len(filenames) == 0 {
+		fmt.Fprintln(os.Stderr, "no rules file given, nor configured")
+		os.Exit(2)
+	}
+
+	valid := true
+	for _, filename := range filenames {
+		set, err := rules.LoadFile(filename)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			valid = false
+			continue
+		}
+		fmt.Printf("%s: %d rules ok\n", filename, len(set.Rules))
+	}
+	if !valid {
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,163 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/mbolis/mogo/config"
+	"github.com/mbolis/mogo/i18n"
+	"github.com/mbolis/mogo/jd"
+)
+
+var serveCommand = command{
+	Name:    "serve",
+	Summary: "serve calendars over HTTP",
+	Options: `    --addr ADDRESS
+        listen on ADDRESS (default: localhost:8080)
+        calendars are served at /calendar, with the query parameters
+        year, month, tz, lang, icons and format (default: html)
+`,
+	Groups: config.SettingsFlags | config.VerdictFlags | config.LocationFlags,
+	Setup: func(fs *config.FlagSet) func(config.Config, []string) {
+		var addr string
+		fs.StringVar(&addr, "addr", "localhost:8080", "")
+		return func(cfg config.Config, _ []string) {
+			runServe(cfg, addr)
+		}
+	},
+}
+
+var contentTypes = map[config.Format]string{
+	config.CSV:      "text/csv; charset=utf-8",
+	config.XLSX:     "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet",
+	config.ODS:      "application/vnd.oasis.opendocument.spreadsheet",
+	config.PDF:      "application/pdf",
+	config.Markdown: "text/markdown; charset=utf-8",
+	config.HTML:     "text/html; charset=utf-8",
+}
+
+var extensions = map[config.Format]string{
+	config.CSV:      "csv",
+	config.XLSX:     "xlsx",
+	config.ODS:      "ods",
+	config.PDF:      "pdf",
+	config.Markdown: "md",
+	config.HTML:     "html",
+}
+
+type server struct {
+	cfg config.Config
+
+	// translations and the time scale are global
+	mu sync.Mutex
+}
+
+func runServe(cfg config.Config, addr string) {
+	s := &server{cfg: cfg}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /{$}", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "/calendar", http.StatusFound)
+	})
+	mux.HandleFunc("GET /calendar", s.calendar)
+
+	log.Printf("listening on http://%s", addr)
+	log.Fatal(http.ListenAndServe(addr, mux))
+}
+
+// requestConfig applies the query parameters of r to the configuration.
+func (s *server) requestConfig(r *http.Request) (config.Config, error) {
+	cfg := s.cfg
+	cfg.Output = ""
+	cfg.Year = time.Now().In(cfg.TZ).Year()
+	if err := cfg.SetFormat("html"); err != nil {
+		return cfg, err
+	}
+
+	q := r.URL.Query()
+	if v := q.Get("year"); v != "" {
+		year, err := strconv.Atoi(v)
+		if err != nil {
+			return cfg, fmt.Errorf("invalid year '%s'", v)
+		}
+		cfg.Year = year
+	}
+	if v := q.Get("month"); v != "" {
+		if err := cfg.SetMonth(v); err != nil {
+			return cfg, err
+		}
+	}
+	if v := q.Get("tz"); v != "" {
+		// TT is no civil time: it has no zones
+		if cfg.TimeScale == jd.TT {
+			return cfg, errors.New("cannot mix the tt time scale and tz")
+		}
+		cfg.TZ = nil
+		if err := cfg.SetTZ(v); err != nil {
+			return cfg, err
+		}
+	}
+	if v := q.Get("lang"); v != "" {
+		if err := cfg.SetLang(v); err != nil {
+			return cfg, err
+		}
+	}
+	if v := q.Get("icons"); v != "" {
+		if err := cfg.SetIconPack(v); err != nil {
+			return cfg, err
+		}
+		if err := cfg.ResolveIcons(); err != nil {
+			return cfg, err
+		}
+	}
+	if v := q.Get("format"); v != "" {
+		if err := cfg.SetFormat(v); err != nil {
+			return cfg, err
+		}
+	}
+	return cfg, nil
+}
+
+func (s *server) calendar(w http.ResponseWriter, r *http.Request) {
+	cfg, err := s.requestConfig(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	var buf bytes.Buffer
+	err = func() error {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+
+		i18n.SetLang(cfg.Lang)
+		jd.SetScale(cfg.TimeScale)
+		start, end := cfg.Range()
+		if err := jd.CheckRange(start, end); err != nil {
+			return err
+		}
+		Generate(cfg, CollectDays(cfg, start, end), &buf)
+		return nil
+	}()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	format := cfg.Format()
+	w.Header().Set("Content-Type", contentTypes[format])
+	if format != config.HTML {
+		filename := "mogo-" + strconv.Itoa(cfg.Year)
+		if cfg.Month != 0 {
+			filename += fmt.Sprintf("-%02d", cfg.Month)
+		}
+		w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename+"."+extensions[format]))
+	}
+	w.Write(buf.Bytes())
+}
@@ -0,0 +1,34 @@
+package main
+
+import (
+	"fmt"
+	"runtime/debug"
+
+	"github.com/mbolis/mogo/config"
+	"github.com/mbolis/mogo/util"
+	"github.com/mshafiee/swephgo"
+)
+
+// version is set at build time with -ldflags "-X main.version=..."
+var version = "dev"
+
+var versionCommand = command{
+	Name:    "version",
+	Summary: "print the version of mogo and of the Swiss Ephemeris",
+	Setup: func(*config.FlagSet) func(config.Config, []string) {
+		return runVersion
+	},
+}
+
+func runVersion(config.Config, []string) {
+	v := version
+	if info, ok := debug.ReadBuildInfo(); ok && v == "dev" && info.Main.Version != "" && info.Main.Version != "(devel)" {
+		v = info.Main.Version
+	}
+
+	var sweVersion [256]byte
+	swephgo.Version(sweVersion[:])
+
+	fmt.Printf("mogo %s\n", v)
+	fmt.Printf("Swiss Ephemeris %s\n", util.NTString(sweVersion[:]))
+}
@@ -0,0 +1,77 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/mbolis/mogo/config"
+	"github.com/mbolis/mogo/i18n"
+)
+
+// command is a subcommand of mogo. Setup registers the options of the
+// command itself and returns the function running it.
+type command struct {
+	Name        string
+	Summary     string
+	Args        string
+	Options     string
+	Groups      config.Group
+	Subcommands []string
+	Setup       func(fs *config.FlagSet) func(cfg config.Config, args []string)
+}
+
+var commands []command
+
+func init() {
+	commands = []command{
+		calendarCommand,
+		eventsCommand,
+		nowCommand,
+		annotateCommand,
+		serveCommand,
+		rulesCommand,
+		versionCommand,
+		completionCommand,
+	}
+}
+
+func commandsByName() map[string]command {
+	byName := make(map[string]command)
+	for _, cmd := range commands {
+		byName[cmd.Name] = cmd
+	}
+	return byName
+}
+
+func (cmd command) FlagSet() (*config.FlagSet, func(config.Config, []string)) {
+	fs := config.NewFlagSet("mogo "+cmd.Name, cmd.Groups)
+	run := cmd.Setup(fs)
+	fs.Usage = func() { cmd.PrintUsage(os.Stderr, fs) }
+	return fs, run
+}
+
+func (cmd command) Run(args []string) {
+	fs, run := cmd.FlagSet()
+	cfg := fs.Parse(args)
+	i18n.SetLang(cfg.Lang)
+	run(cfg, fs.Args())
+}
+
+func (cmd command) PrintUsage(w io.Writer, fs *config.FlagSet) {
+	synopsis := strings.TrimSpace("mogo " + cmd.Name + " [options] " + cmd.Args)
+	fmt.Fprintf(w, "usage: %s\n\n%s\n\n", synopsis, cmd.Summary)
+	fs.PrintOptions(w, cmd.Options)
+}
+
+func printUsage(w io.Writer) {
+	fmt.Fprint(w, "usage: mogo [command] [options]\n\ncommands:\n")
+	for _, cmd := range commands {
+		fmt.Fprintf(w, "    %-12s%s\n", cmd.Name, cmd.Summary)
+	}
+	fmt.Fprint(w, "\nwithout a command, 'calendar' is run; 'mogo COMMAND -h' describes the options of COMMAND\n\n")
+
+	fs, _ := calendarCommand.FlagSet()
+	fs.PrintOptions(w, calendarCommand.Options)
+}
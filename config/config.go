@@ -2,27 +2,72 @@ package config
 
 import (
 	"errors"
-	"flag"
 	"fmt"
 	"os"
 	"path"
+	"path/filepath"
 	"regexp"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
 
 	"github.com/jeandeaual/go-locale"
 	"github.com/mbolis/mogo/icons"
+	"github.com/mbolis/mogo/jd"
+	"github.com/mbolis/mogo/lunarday"
+	"github.com/mbolis/mogo/model"
+	"github.com/mbolis/mogo/observer"
+	"github.com/mbolis/mogo/rules"
+	"github.com/mbolis/mogo/sign"
 	"golang.org/x/text/language"
 )
 
 type Config struct {
-	Year   int
-	Month  time.Month
-	TZ     *time.Location
-	Icons  icons.Style
-	Output string
-	Lang   language.Tag
+	Year     int
+	Month    time.Month
+	Years    int
+	Calendar model.Calendar
+	TZ       *time.Location
+	Icons    icons.Style
+	Output   string
+	Lang     language.Tag
+
+	UTCColumn          bool
+	IlluminationColumn bool
+	AgeColumn          bool
+	LunarDayColumn     bool
+	ElementColumn      bool
+	TUI                bool
+
+	IconsFile  string
+	ASCII      bool
+	ImageIcons bool
+	iconPack   string
+	packDefs   map[string]icons.PackDef
+
+	Location  *observer.Location
+	LunarDay  lunarday.Mode
+	latitude  *float64
+	longitude *float64
+	elevation float64
+	city      string
+
+	Zodiac        sign.Zodiac
+	elementZodiac *sign.Zodiac
+
+	Precision model.Precision
+	Rounding  model.Rounding
+	TimeScale jd.Scale
+
+	Profile    string
+	Salon      Salon
+	RulesFile  string
+	Rules      *rules.Set
+	configFile string
+
+	format        *Format
+	defaultFormat *Format
 }
 
 type Format int
@@ -32,12 +77,49 @@ const (
 	XLSX
 	ODS
 	PDF
+	Markdown
+	HTML
 )
 
+var formatsByName = map[string]Format{
+	"csv":      CSV,
+	"xlsx":     XLSX,
+	"ods":      ODS,
+	"pdf":      PDF,
+	"md":       Markdown,
+	"markdown": Markdown,
+	"html":     HTML,
+}
+
+func parseFormat(s string) (*Format, error) {
+	f, ok := formatsByName[strings.ToLower(s)]
+	if !ok {
+		return nil, fmt.Errorf("unrecognized format '%s'", s)
+	}
+	return &f, nil
+}
+
+func (c *Config) SetFormat(s string) (err error) {
+	c.format, err = parseFormat(s)
+	return
+}
+
+// Format returns the output format: the one given on the command line,
+// else the one inferred by the output file extension, else the default
+// one from the settings.
 func (c Config) Format() Format {
 	ext := path.Ext(c.Output)
+	switch {
+	case c.format != nil:
+		return *c.format
+	case ext == "" && c.defaultFormat != nil:
+		return *c.defaultFormat
+	case ext == "":
+		return CSV
+	}
+
 	switch ext {
-	case ".csv", ".txt", "":
+	case ".csv", ".txt":
 		return CSV
 	case ".xlsx":
 		return XLSX
@@ -45,18 +127,22 @@ func (c Config) Format() Format {
 		return ODS
 	case ".pdf":
 		return PDF
+	case ".md", ".markdown":
+		return Markdown
+	case ".html", ".htm":
+		return HTML
 	default:
 		panic("unrecognized file extension: " + ext)
 	}
 }
 
 func (c Config) Range() (start, end time.Time) {
-	start = time.Date(c.Year, c.Month, 1, 0, 0, 0, 0, c.TZ)
 	if c.Month == 0 {
-		start = start.AddDate(0, 1, 0)
-		end = start.AddDate(1, 0, 0)
+		start = c.Calendar.Midnight(c.Year, time.January, 1, c.TZ)
+		end = c.Calendar.Midnight(c.Year+max(c.Years, 1), time.January, 1, c.TZ)
 	} else {
-		end = start.AddDate(0, 1, 0)
+		start = c.Calendar.Midnight(c.Year, c.Month, 1, c.TZ)
+		end = c.Calendar.Midnight(c.Year, c.Month+1, 1, c.TZ)
 	}
 	return
 }
@@ -98,6 +184,15 @@ func (c *Config) SetMonth(s string) error {
 	return nil
 }
 
+func (c *Config) SetYears(s string) error {
+	n, err := strconv.Atoi(s)
+	if err != nil || n < 1 {
+		return fmt.Errorf("invalid number of years '%s'", s)
+	}
+	c.Years = n
+	return nil
+}
+
 func (c *Config) SetTZ(s string) (err error) {
 	if c.TZ != nil {
 		return errors.New("cannot mix -tz and -utc")
@@ -116,99 +211,332 @@ func (c *Config) SetUTC(string) error {
 	return nil
 }
 
-var iconsPackRegex = regexp.MustCompile(`(?i)^(arrows|thumbs|semaphore)$`)
+func (c *Config) SetIconPack(s string) error {
+	if s == "" {
+		return errors.New("missing icons style")
+	}
 
-var iconsPacksByName = map[string]icons.Style{
-	"arrows":    icons.Arrows,
-	"thumbs":    icons.Thumbs,
-	"semaphore": icons.Semaphore,
+	c.iconPack = strings.ToLower(s)
+	return nil
 }
 
-func (c *Config) SetIconPack(s string) error {
-	name := iconsPackRegex.FindString(s)
-	if name == "" {
-		return fmt.Errorf("unrecognized icons style '%s'", s)
+// IconPacks returns the builtin icon packs along with the ones
+// defined in configuration files and in the icons file, if any.
+func (c *Config) IconPacks() (map[string]icons.Style, error) {
+	packs := icons.Builtin()
+
+	custom, err := icons.Build(c.packDefs)
+	if err != nil {
+		return nil, err
+	}
+	for name, style := range custom {
+		packs[name] = style
+	}
+
+	filename := c.IconsFile
+	if filename == "" {
+		dir, err := os.UserConfigDir()
+		if err != nil {
+			return packs, nil
+		}
+		filename = filepath.Join(dir, "mogo", "icons.toml")
+		if _, err := os.Stat(filename); err != nil {
+			return packs, nil
+		}
+	}
+
+	f, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	custom, err = icons.Load(f)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", filename, err)
+	}
+	for name, style := range custom {
+		packs[name] = style
+	}
+	return packs, nil
+}
+
+// ResolveIcons selects the icon pack set by SetIconPack.
+func (c *Config) ResolveIcons() error {
+	packs, err := c.IconPacks()
+	if err != nil {
+		return err
+	}
+
+	style, ok := packs[c.iconPack]
+	if !ok {
+		var names []string
+		for name := range packs {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		return fmt.Errorf("unrecognized icons style '%s', expected one of: %s", c.iconPack, strings.Join(names, ", "))
+	}
+
+	if c.ASCII {
+		style = style.PlainText()
+	}
+	c.Icons = style
+	return nil
+}
+
+func (c *Config) SetLatitude(s string) error {
+	lat, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return fmt.Errorf("invalid latitude '%s'", s)
+	}
+	c.latitude = &lat
+	return nil
+}
+
+func (c *Config) SetLongitude(s string) error {
+	lon, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return fmt.Errorf("invalid longitude '%s'", s)
+	}
+	c.longitude = &lon
+	return nil
+}
+
+func (c *Config) SetElevation(s string) (err error) {
+	c.elevation, err = strconv.ParseFloat(s, 64)
+	if err != nil {
+		return fmt.Errorf("invalid elevation '%s'", s)
+	}
+	return nil
+}
+
+func (c *Config) SetCity(s string) error {
+	if s == "" {
+		return errors.New("missing city")
+	}
+	c.city = s
+	return nil
+}
+
+// resolveLocation sets Location from either the city or the coordinates.
+func (c *Config) resolveLocation() error {
+	switch {
+	case c.city != "" && (c.latitude != nil || c.longitude != nil):
+		return errors.New("cannot mix --city and --lat/--lon")
+	case c.city != "":
+		loc, err := observer.Lookup(c.city)
+		if err != nil {
+			return err
+		}
+		c.Location = &loc
+	case c.latitude != nil && c.longitude != nil:
+		loc := observer.Location{Latitude: *c.latitude, Longitude: *c.longitude, Elevation: c.elevation}
+		if err := loc.Validate(); err != nil {
+			return err
+		}
+		c.Location = &loc
+	case c.latitude != nil || c.longitude != nil:
+		return errors.New("both --lat and --lon are required")
 	}
 
-	name = strings.ToLower(name)
-	c.Icons = iconsPacksByName[name]
+	if c.LunarDay == lunarday.Moonrise && c.Location == nil {
+		return errors.New("lunar days from moonrise require a place: give --city, or --lat and --lon")
+	}
+	return nil
+}
+
+func (c *Config) SetLunarDay(s string) (err error) {
+	c.LunarDay, err = lunarday.ParseMode(s)
+	return
+}
+
+func (c *Config) SetZodiac(s string) (err error) {
+	c.Zodiac, err = sign.ParseZodiac(s)
+	return
+}
+
+func (c *Config) SetElementZodiac(s string) error {
+	z, err := sign.ParseZodiac(s)
+	if err != nil {
+		return err
+	}
+	c.elementZodiac = &z
 	return nil
 }
 
+// ElementZodiac divides the signs the biodynamic elements are derived
+// from, which are the signs of the calendar unless told otherwise.
+func (c Config) ElementZodiac() sign.Zodiac {
+	if c.elementZodiac != nil {
+		return *c.elementZodiac
+	}
+	return c.Zodiac
+}
+
+func (c *Config) SetPrecision(s string) (err error) {
+	c.Precision, err = model.ParsePrecision(s)
+	return
+}
+
+func (c *Config) SetRounding(s string) (err error) {
+	c.Rounding, err = model.ParseRounding(s)
+	return
+}
+
+func (c *Config) SetCalendar(s string) (err error) {
+	c.Calendar, err = model.ParseCalendar(s)
+	return
+}
+
+func (c *Config) SetTimeScale(s string) (err error) {
+	c.TimeScale, err = jd.ParseScale(s)
+	return
+}
+
 func (c *Config) SetLang(s string) (err error) {
 	c.Lang, err = language.Parse(s)
 	return
 }
 
-const usage = `supported options:
-    -y YEAR
-    --year YEAR
-        ephemeris will be calculated for the duration of YEAR (default: current year)
-    -m MONTH
-    --month MONTH
-        if specified, the calculation will be restricted to MONTH
-        can be either a number [1-12], or short or long name (jan/january, ...)
-    -z TIMEZONE
-    --tz TIMEZONE
-        output time is local to TIMEZONE (default: system timezone)
-        cannot be specified along with --utc
-    -u
-    --utc
-        shortcut for '--tz UTC'
-        cannot be specified along with --tz
-    -i ICONS
-    --icons ICONS
-        the icons style to be used for indicators in the output
-        can be one of: arrows, thumbs, semaphore (default: arrows)
-    -o FILENAME
-    --output FILENAME
-        optional path to an output file, '-' for stdout (default: -)
-        if the file name has an extension, it will be used to infer the format, otherwise CSV is assumed
-    -l LANGUAGE
-    --lang LANGUAGE
-        translate the output into LANGUAGE if supported (default: system language)
-        LANGUAGE must be a valid BCP 47 language string
-    -h
-    --help
-        display this help message`
-
-func Parse() (config Config) {
-	currentYear := time.Now().Year()
-	flag.IntVar(&config.Year, "y", currentYear, "")
-	flag.IntVar(&config.Year, "year", currentYear, "")
-
-	flag.Func("m", "", config.SetMonth)
-	flag.Func("month", "", config.SetMonth)
-
-	config.TZ = time.Local
-	flag.Func("z", "", config.SetTZ)
-	flag.Func("tz", "", config.SetTZ)
-	flag.Func("timezone", "", config.SetTZ)
-
-	flag.BoolFunc("u", "", config.SetUTC)
-	flag.BoolFunc("utc", "", config.SetUTC)
-
-	flag.Func("i", "", config.SetIconPack)
-	flag.Func("icon", "", config.SetIconPack)
-	flag.Func("icons", "", config.SetIconPack)
-
-	flag.StringVar(&config.Output, "o", "-", "")
-	flag.StringVar(&config.Output, "out", "-", "")
-	flag.StringVar(&config.Output, "output", "-", "")
-
+// systemLanguage falls back to English when the locale cannot be detected.
+func systemLanguage() language.Tag {
 	lang, err := locale.GetLanguage()
 	if err != nil {
-		panic(err)
+		return language.English
+	}
+	tag, err := language.Parse(lang)
+	if err != nil {
+		return language.English
 	}
-	config.Lang, err = language.Parse(lang)
+	return tag
+}
+
+// applySettings loads configuration files, the selected profile and
+// environment variables, and applies them where no flag was given.
+func (c *Config) applySettings(set map[string]bool) error {
+	f, err := loadFiles(c.configFile)
 	if err != nil {
-		panic(err)
+		return err
 	}
-	flag.Func("l", "", config.SetLang)
-	flag.Func("lang", "", config.SetLang)
+	c.packDefs = f.Packs
 
-	flag.Usage = func() { fmt.Fprintln(os.Stderr, usage) }
-	flag.Parse()
+	settings := f.Settings
 
-	return config
+	if c.Profile == "" {
+		c.Profile = os.Getenv("MOGO_PROFILE")
+	}
+	if c.Profile == "" {
+		c.Profile = f.Profile
+	}
+	if c.Profile != "" {
+		profile, ok := f.Profiles[c.Profile]
+		if !ok {
+			return fmt.Errorf("unknown profile '%s'", c.Profile)
+		}
+		settings = settings.merge(profile)
+	}
+
+	env, err := EnvSettings()
+	if err != nil {
+		return err
+	}
+	settings = settings.merge(env)
+
+	return c.apply(settings, set)
+}
+
+// apply sets the values in s, unless one of the corresponding flags is set.
+func (c *Config) apply(s Settings, set map[string]bool) error {
+	isSet := func(names ...string) bool {
+		for _, name := range names {
+			if set[name] {
+				return true
+			}
+		}
+		return false
+	}
+
+	var errs []error
+	if s.TZ != nil && !isSet("z", "tz", "timezone", "u", "utc") {
+		errs = append(errs, c.SetTZ(*s.TZ))
+	}
+	if s.Lang != nil && !isSet("l", "lang") {
+		errs = append(errs, c.SetLang(*s.Lang))
+	}
+	if s.Icons != nil && !isSet("i", "icon", "icons") {
+		errs = append(errs, c.SetIconPack(*s.Icons))
+	}
+	if s.IconsFile != nil && !isSet("icons-file") {
+		c.IconsFile = *s.IconsFile
+	}
+	if s.ASCII != nil && !isSet("ascii") {
+		c.ASCII = *s.ASCII
+	}
+	if s.ImageIcons != nil && !isSet("image-icons") {
+		c.ImageIcons = *s.ImageIcons
+	}
+	if s.UTCColumn != nil && !isSet("utc-column") {
+		c.UTCColumn = *s.UTCColumn
+	}
+	if s.IlluminationColumn != nil && !isSet("illumination-column") {
+		c.IlluminationColumn = *s.IlluminationColumn
+	}
+	if s.AgeColumn != nil && !isSet("age-column") {
+		c.AgeColumn = *s.AgeColumn
+	}
+	if s.LunarDayColumn != nil && !isSet("lunar-day-column") {
+		c.LunarDayColumn = *s.LunarDayColumn
+	}
+	if s.ElementColumn != nil && !isSet("element-column") {
+		c.ElementColumn = *s.ElementColumn
+	}
+	if s.Zodiac != nil && !isSet("zodiac") {
+		errs = append(errs, c.SetZodiac(*s.Zodiac))
+	}
+	if s.ElementZodiac != nil && !isSet("element-zodiac") {
+		errs = append(errs, c.SetElementZodiac(*s.ElementZodiac))
+	}
+	if s.Precision != nil && !isSet("precision") {
+		errs = append(errs, c.SetPrecision(*s.Precision))
+	}
+	if s.Rounding != nil && !isSet("rounding") {
+		errs = append(errs, c.SetRounding(*s.Rounding))
+	}
+	if s.TimeScale != nil && !isSet("time-scale") {
+		errs = append(errs, c.SetTimeScale(*s.TimeScale))
+	}
+	if s.Calendar != nil && !isSet("calendar") {
+		errs = append(errs, c.SetCalendar(*s.Calendar))
+	}
+	if s.LunarDay != nil && !isSet("lunar-day") {
+		errs = append(errs, c.SetLunarDay(*s.LunarDay))
+	}
+	if s.Output != nil && !isSet("o", "out", "output") {
+		c.Output = *s.Output
+	}
+	if s.Format != nil {
+		var err error
+		c.defaultFormat, err = parseFormat(*s.Format)
+		errs = append(errs, err)
+	}
+	if s.Rules != nil && !isSet("rules") {
+		c.RulesFile = *s.Rules
+	}
+	if s.City != nil && !isSet("city", "lat", "latitude", "lon", "longitude") {
+		errs = append(errs, c.SetCity(*s.City))
+	}
+	if s.Latitude != nil && !isSet("lat", "latitude", "city") {
+		c.latitude = s.Latitude
+	}
+	if s.Longitude != nil && !isSet("lon", "longitude", "city") {
+		c.longitude = s.Longitude
+	}
+	if s.Elevation != nil && !isSet("elevation") {
+		c.elevation = *s.Elevation
+	}
+	if s.Salon != nil {
+		c.Salon = *s.Salon
+	}
+	return errors.Join(errs...)
 }
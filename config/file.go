@@ -0,0 +1,275 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+
+	"github.com/BurntSushi/toml"
+	"github.com/mbolis/mogo/icons"
+)
+
+// Salon holds the details printed on calendars given to customers.
+type Salon struct {
+	Name    string `toml:"name"`
+	Address string `toml:"address"`
+	Phone   string `toml:"phone"`
+	Email   string `toml:"email"`
+	Website string `toml:"website"`
+}
+
+// Settings are defaults which can be given in configuration files,
+// profiles and environment variables. Unset values are nil.
+type Settings struct {
+	TZ         *string `toml:"tz"`
+	Lang       *string `toml:"lang"`
+	Icons      *string `toml:"icons"`
+	IconsFile  *string `toml:"icons_file"`
+	ASCII      *bool   `toml:"ascii"`
+	ImageIcons *bool   `toml:"image_icons"`
+	UTCColumn  *bool   `toml:"utc_column"`
+	Output     *string `toml:"output"`
+	Format     *string `toml:"format"`
+	Rules      *string `toml:"rules"`
+	Salon      *Salon  `toml:"salon"`
+
+	City      *string  `toml:"city"`
+	Latitude  *float64 `toml:"lat"`
+	Longitude *float64 `toml:"lon"`
+	Elevation *float64 `toml:"elevation"`
+	LunarDay  *string  `toml:"lunar_day"`
+
+	IlluminationColumn *bool `toml:"illumination_column"`
+	AgeColumn          *bool `toml:"age_column"`
+	LunarDayColumn     *bool `toml:"lunar_day_column"`
+	ElementColumn      *bool `toml:"element_column"`
+
+	Zodiac        *string `toml:"zodiac"`
+	ElementZodiac *string `toml:"element_zodiac"`
+
+	Precision *string `toml:"precision"`
+	Rounding  *string `toml:"rounding"`
+	TimeScale *string `toml:"time_scale"`
+	Calendar  *string `toml:"calendar"`
+}
+
+// File is the content of a configuration file:
+//
+//	tz = "Europe/Rome"
+//	lang = "it"
+//	profile = "shop-rome"
+//
+//	[salon]
+//	name = "Beauty & Moon"
+//
+//	[profiles.shop-milan]
+//	output = "milan.xlsx"
+//	salon = { name = "Beauty & Moon Milano" }
+//
+//	[packs.spa]
+//	positive = "🌿"
+//	...
+type File struct {
+	Settings
+	Profile  string                   `toml:"profile"`
+	Profiles map[string]Settings      `toml:"profiles"`
+	Packs    map[string]icons.PackDef `toml:"packs"`
+}
+
+// UserFile returns the path of the per-user configuration file.
+func UserFile() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "mogo", "config.toml"), nil
+}
+
+// ProjectFile is the configuration file looked up in the working directory.
+const ProjectFile = "mogo.toml"
+
+func LoadFile(filename string) (f File, err error) {
+	md, err := toml.DecodeFile(filename, &f)
+	if err != nil {
+		return f, err
+	}
+	if undecoded := md.Undecoded(); len(undecoded) > 0 {
+		return f, fmt.Errorf("%s: unknown settings: %v", filename, undecoded)
+	}
+	return f, nil
+}
+
+// loadFiles merges the user and project configuration files, or reads
+// only filename if given.
+func loadFiles(filename string) (File, error) {
+	if filename != "" {
+		return LoadFile(filename)
+	}
+
+	var filenames []string
+	if user, err := UserFile(); err == nil {
+		filenames = append(filenames, user)
+	}
+	filenames = append(filenames, ProjectFile)
+
+	var merged File
+	for _, filename := range filenames {
+		if _, err := os.Stat(filename); errors.Is(err, os.ErrNotExist) {
+			continue
+		}
+
+		f, err := LoadFile(filename)
+		if err != nil {
+			return merged, err
+		}
+		merged = merged.merge(f)
+	}
+	return merged, nil
+}
+
+func (f File) merge(other File) File {
+	f.Settings = f.Settings.merge(other.Settings)
+	if other.Profile != "" {
+		f.Profile = other.Profile
+	}
+	f.Profiles = mergeMaps(f.Profiles, other.Profiles, Settings.merge)
+	f.Packs = mergeMaps(f.Packs, other.Packs, func(_, pack icons.PackDef) icons.PackDef { return pack })
+	return f
+}
+
+func mergeMaps[T any](m, other map[string]T, merge func(T, T) T) map[string]T {
+	merged := make(map[string]T)
+	for k, v := range m {
+		merged[k] = v
+	}
+	for k, v := range other {
+		if prev, ok := merged[k]; ok {
+			v = merge(prev, v)
+		}
+		merged[k] = v
+	}
+	return merged
+}
+
+// merge returns s overridden by the values set in other.
+func (s Settings) merge(other Settings) Settings {
+	override(&s.TZ, other.TZ)
+	override(&s.Lang, other.Lang)
+	override(&s.Icons, other.Icons)
+	override(&s.IconsFile, other.IconsFile)
+	override(&s.ASCII, other.ASCII)
+	override(&s.ImageIcons, other.ImageIcons)
+	override(&s.UTCColumn, other.UTCColumn)
+	override(&s.IlluminationColumn, other.IlluminationColumn)
+	override(&s.AgeColumn, other.AgeColumn)
+	override(&s.Output, other.Output)
+	override(&s.Format, other.Format)
+	override(&s.Rules, other.Rules)
+	override(&s.Salon, other.Salon)
+	// a place is either a city or coordinates: the one set last wins
+	if other.City != nil {
+		s.Latitude, s.Longitude = nil, nil
+	}
+	if other.Latitude != nil || other.Longitude != nil {
+		s.City = nil
+	}
+	override(&s.City, other.City)
+	override(&s.Latitude, other.Latitude)
+	override(&s.Longitude, other.Longitude)
+	override(&s.Elevation, other.Elevation)
+	override(&s.LunarDay, other.LunarDay)
+	override(&s.LunarDayColumn, other.LunarDayColumn)
+	override(&s.ElementColumn, other.ElementColumn)
+	override(&s.Zodiac, other.Zodiac)
+	override(&s.ElementZodiac, other.ElementZodiac)
+	override(&s.Precision, other.Precision)
+	override(&s.Rounding, other.Rounding)
+	override(&s.TimeScale, other.TimeScale)
+	override(&s.Calendar, other.Calendar)
+	return s
+}
+
+func override[T any](dst **T, src *T) {
+	if src != nil {
+		*dst = src
+	}
+}
+
+// EnvSettings reads settings from MOGO_* environment variables.
+func EnvSettings() (s Settings, err error) {
+	s.TZ = lookupEnv("MOGO_TZ")
+	s.Lang = lookupEnv("MOGO_LANG")
+	s.Icons = lookupEnv("MOGO_ICONS")
+	s.IconsFile = lookupEnv("MOGO_ICONS_FILE")
+	s.Output = lookupEnv("MOGO_OUTPUT")
+	s.Format = lookupEnv("MOGO_FORMAT")
+	s.Rules = lookupEnv("MOGO_RULES")
+	s.City = lookupEnv("MOGO_CITY")
+	s.LunarDay = lookupEnv("MOGO_LUNAR_DAY")
+	s.Zodiac = lookupEnv("MOGO_ZODIAC")
+	s.ElementZodiac = lookupEnv("MOGO_ELEMENT_ZODIAC")
+	s.Precision = lookupEnv("MOGO_PRECISION")
+	s.Rounding = lookupEnv("MOGO_ROUNDING")
+	s.TimeScale = lookupEnv("MOGO_TIME_SCALE")
+	s.Calendar = lookupEnv("MOGO_CALENDAR")
+
+	var errs []error
+	s.ASCII, err = lookupEnvBool("MOGO_ASCII")
+	errs = append(errs, err)
+	s.ImageIcons, err = lookupEnvBool("MOGO_IMAGE_ICONS")
+	errs = append(errs, err)
+	s.UTCColumn, err = lookupEnvBool("MOGO_UTC_COLUMN")
+	errs = append(errs, err)
+	s.IlluminationColumn, err = lookupEnvBool("MOGO_ILLUMINATION_COLUMN")
+	errs = append(errs, err)
+	s.AgeColumn, err = lookupEnvBool("MOGO_AGE_COLUMN")
+	errs = append(errs, err)
+	s.LunarDayColumn, err = lookupEnvBool("MOGO_LUNAR_DAY_COLUMN")
+	errs = append(errs, err)
+	s.ElementColumn, err = lookupEnvBool("MOGO_ELEMENT_COLUMN")
+	errs = append(errs, err)
+
+	s.Latitude, err = lookupEnvFloat("MOGO_LAT")
+	errs = append(errs, err)
+	s.Longitude, err = lookupEnvFloat("MOGO_LON")
+	errs = append(errs, err)
+	s.Elevation, err = lookupEnvFloat("MOGO_ELEVATION")
+	errs = append(errs, err)
+
+	return s, errors.Join(errs...)
+}
+
+func lookupEnv(key string) *string {
+	if v, ok := os.LookupEnv(key); ok && v != "" {
+		return &v
+	}
+	return nil
+}
+
+func lookupEnvBool(key string) (*bool, error) {
+	v := lookupEnv(key)
+	if v == nil {
+		return nil, nil
+	}
+
+	b, err := strconv.ParseBool(*v)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", key, err)
+	}
+	return &b, nil
+}
+
+func lookupEnvFloat(key string) (*float64, error) {
+	v := lookupEnv(key)
+	if v == nil {
+		return nil, nil
+	}
+
+	f, err := strconv.ParseFloat(*v, 64)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", key, err)
+	}
+	return &f, nil
+}
@@ -0,0 +1,57 @@
+package config
+
+import (
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestPlaceLayers(t *testing.T) {
+	tests := []struct {
+		name, file string
+		env        map[string]string
+		lat        float64
+	}{
+		{
+			"env city over profile coordinates",
+			"profile = \"milan\"\n[profiles.milan]\nlat = 45.46\nlon = 9.19\n",
+			map[string]string{"MOGO_CITY": "Rome"},
+			41.89,
+		},
+		{
+			"env coordinates over file city",
+			"city = \"Rome\"\n",
+			map[string]string{"MOGO_LAT": "45.46", "MOGO_LON": "9.19"},
+			45.46,
+		},
+		{
+			"profile city over file coordinates",
+			"lat = 45.46\nlon = 9.19\nprofile = \"rome\"\n[profiles.rome]\ncity = \"Rome\"\n",
+			nil,
+			41.89,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			for _, key := range []string{"MOGO_PROFILE", "MOGO_CITY", "MOGO_LAT", "MOGO_LON"} {
+				t.Setenv(key, test.env[key])
+			}
+			filename := filepath.Join(t.TempDir(), "mogo.toml")
+			if err := os.WriteFile(filename, []byte(test.file), 0o644); err != nil {
+				t.Fatal(err)
+			}
+
+			c := Config{configFile: filename}
+			if err := c.applySettings(map[string]bool{}); err != nil {
+				t.Fatal(err)
+			}
+			if err := c.resolveLocation(); err != nil {
+				t.Fatal(err)
+			}
+			if c.Location == nil || math.Abs(c.Location.Latitude-test.lat) > 0.1 {
+				t.Errorf("got location %v, want latitude %g", c.Location, test.lat)
+			}
+		})
+	}
+}
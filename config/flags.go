@@ -0,0 +1,345 @@
+package config
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/mbolis/mogo/icons"
+	"github.com/mbolis/mogo/jd"
+	"github.com/mbolis/mogo/rules"
+)
+
+// Group is a set of related options, which commands register together.
+type Group int
+
+const (
+	SettingsFlags Group = 1 << iota // configuration files, profile, timezone and language
+	VerdictFlags                    // icons and rules
+	PeriodFlags                     // year and month
+	OutputFlags                     // output file
+	CalendarFlags                   // format and layout of calendars
+	LocationFlags                   // place of the observer and reckoning of lunar days
+
+	AllFlags = SettingsFlags | VerdictFlags | PeriodFlags | OutputFlags | CalendarFlags | LocationFlags
+)
+
+const usageSettings = `    -z TIMEZONE
+    --tz TIMEZONE
+        output time is local to TIMEZONE (default: system timezone)
+        times off the standard offset, as in daylight saving time, are followed by their UTC offset
+        cannot be specified along with --utc
+    -u
+    --utc
+        shortcut for '--tz UTC'
+        cannot be specified along with --tz
+    -l LANGUAGE
+    --lang LANGUAGE
+        translate the output into LANGUAGE if supported (default: system language)
+        LANGUAGE must be a valid BCP 47 language string
+    --zodiac ZODIAC
+        how the ecliptic is divided among the signs the Moon is in, for the calendar and the verdicts
+        can be one of: tropical (equal signs of 30°), constellations (astronomical constellations
+        along the ecliptic, Ophiuchus included in Scorpio), iau (IAU constellation boundaries,
+        Ophiuchus included) (default: tropical)
+    --precision PRECISION
+        the unit event times are given in
+        can be one of: minute, second (default: minute)
+    --rounding ROUNDING
+        how event times are brought to the precision
+        can be one of: nearest, floor, ceil (default: nearest)
+    --time-scale SCALE
+        the time scale of the times read and written
+        can be one of: ut (Universal Time, the civil time of clocks), tt (Terrestrial Time, the uniform
+        time of the ephemeris, ahead of UT by Delta-T: about 69s today, hours in antiquity) (default: ut)
+        times in TT are given in UTC: cannot be specified along with --tz
+    --config FILENAME
+        read defaults from FILENAME only
+        otherwise <user config dir>/mogo/config.toml and ./mogo.toml are merged, the latter taking precedence
+    -p PROFILE
+    --profile PROFILE
+        apply the settings of the named profile from the configuration files
+        (default: $MOGO_PROFILE, or the 'profile' setting of the configuration files)
+`
+
+const usageVerdicts = `    -i ICONS
+    --icons ICONS
+        the icons style to be used for indicators in the output
+        can be one of: arrows, thumbs, semaphore, ascii, or a pack defined in the icons file (default: arrows)
+    --icons-file FILENAME
+        path to a TOML file defining custom icon packs (default: <user config dir>/mogo/icons.toml)
+    --ascii
+        use the plain-ASCII fallback of the icons style, for printers without emoji
+    --rules FILENAME
+        path to a TOML file overriding the built-in verdicts of the treatments
+`
+
+const usagePeriod = `    -y YEAR
+    --year YEAR
+        ephemeris will be calculated for the duration of YEAR (default: current year)
+    -m MONTH
+    --month MONTH
+        if specified, the calculation will be restricted to MONTH
+        can be either a number [1-12], or short or long name (jan/january, ...)
+    --years N
+        extend the calculation to N years from YEAR (default: 1)
+        cannot be specified along with --month
+    --calendar CALENDAR
+        the calendar of YEAR, MONTH and of the dates shown
+        can be one of: gregorian, julian (both proleptic), reform (Julian until 1582-10-04, then Gregorian),
+        or the date of the first Gregorian day, e.g. 1752-09-14 (default: gregorian)
+        the ephemeris covers the years from -3000 to 3000
+`
+
+const usageOutput = `    -o FILENAME
+    --output FILENAME
+        optional path to an output file, '-' for stdout (default: -)
+`
+
+const usageCalendar = `        if the file name has an extension, it will be used to infer the format, otherwise CSV is assumed
+        supported extensions: .csv, .txt, .xlsx, .ods, .pdf, .md, .html
+    -f FORMAT
+    --format FORMAT
+        output format, overriding the one inferred from the file name
+        can be one of: csv, xlsx, ods, pdf, md, html
+    --image-icons
+        embed pictures instead of emoji for phases, signs and verdicts (XLSX, ODS and PDF only)
+    --utc-column
+        add a column holding the UTC date and time of each event
+    --illumination-column
+        add a column holding the illuminated fraction of the Moon's disc
+    --age-column
+        add a column holding the age of the Moon, in days since the last new moon
+    --lunar-day-column
+        add columns holding the lunar day and the times it changes during the day
+    --element-column
+        add columns holding the biodynamic element of the day (root, flower, leaf or fruit)
+        and the time it changes
+    --element-zodiac ZODIAC
+        how the signs the elements are derived from are divided
+        can be one of: tropical, constellations, iau, as for --zodiac (default: same as --zodiac)
+    --tui
+        browse the calendar interactively in the terminal
+        when writing to a terminal without --tui, an aligned table is printed instead of CSV
+`
+
+const usageLocation = `    --lat LATITUDE
+    --lon LONGITUDE
+        place of the observer, in decimal degrees (north and east are positive)
+        enables the times of sunrise, sunset, moonrise and moonset
+    --elevation METERS
+        elevation of the observer above sea level (default: 0)
+    --city CITY
+        take the place of the observer from a bundled list of cities, e.g. 'Rome' or 'Roma, IT'
+        cannot be specified along with --lat and --lon
+    --lunar-day MODE
+        how lunar days are numbered, referenced by rules and shown by --lunar-day-column
+        can be one of: tithi (every 12° of elongation from new moon), moonrise (from new moon,
+        then at every moonrise; requires a place) (default: tithi)
+`
+
+const usageHelp = `    -h
+    --help
+        display this help message
+`
+
+const usagePrecedence = `
+settings are taken, from highest to lowest precedence, from: command-line options,
+environment variables (MOGO_TZ, MOGO_LANG, MOGO_ICONS, MOGO_ICONS_FILE, MOGO_ASCII,
+MOGO_IMAGE_ICONS, MOGO_UTC_COLUMN, MOGO_ILLUMINATION_COLUMN, MOGO_AGE_COLUMN,
+MOGO_LUNAR_DAY_COLUMN, MOGO_ELEMENT_COLUMN, MOGO_OUTPUT, MOGO_FORMAT, MOGO_RULES,
+MOGO_CITY, MOGO_LAT, MOGO_LON, MOGO_ELEVATION, MOGO_LUNAR_DAY, MOGO_ZODIAC,
+MOGO_ELEMENT_ZODIAC, MOGO_PRECISION, MOGO_ROUNDING, MOGO_TIME_SCALE, MOGO_CALENDAR),
+the selected profile, and the configuration files
+`
+
+// FlagSet parses the options of a command into a Config.
+// Commands may register options of their own on the embedded flag.FlagSet.
+type FlagSet struct {
+	*flag.FlagSet
+	config *Config
+	groups Group
+}
+
+func NewFlagSet(name string, groups Group) *FlagSet {
+	fs := &FlagSet{
+		FlagSet: flag.NewFlagSet(name, flag.ExitOnError),
+		config:  &Config{Lang: systemLanguage(), iconPack: icons.Arrows.Name},
+		groups:  groups,
+	}
+	config := fs.config
+
+	if groups&PeriodFlags != 0 {
+		currentYear := time.Now().Year()
+		fs.IntVar(&config.Year, "y", currentYear, "")
+		fs.IntVar(&config.Year, "year", currentYear, "")
+
+		fs.Func("m", "", config.SetMonth)
+		fs.Func("month", "", config.SetMonth)
+		fs.Func("years", "", config.SetYears)
+		fs.Func("calendar", "", config.SetCalendar)
+	}
+
+	if groups&SettingsFlags != 0 {
+		fs.Func("z", "", config.SetTZ)
+		fs.Func("tz", "", config.SetTZ)
+		fs.Func("timezone", "", config.SetTZ)
+
+		fs.BoolFunc("u", "", config.SetUTC)
+		fs.BoolFunc("utc", "", config.SetUTC)
+
+		fs.Func("l", "", config.SetLang)
+		fs.Func("lang", "", config.SetLang)
+
+		fs.Func("zodiac", "", config.SetZodiac)
+		fs.Func("precision", "", config.SetPrecision)
+		fs.Func("rounding", "", config.SetRounding)
+		fs.Func("time-scale", "", config.SetTimeScale)
+
+		fs.StringVar(&config.configFile, "config", "", "")
+		fs.StringVar(&config.Profile, "p", "", "")
+		fs.StringVar(&config.Profile, "profile", "", "")
+	}
+
+	if groups&VerdictFlags != 0 {
+		fs.Func("i", "", config.SetIconPack)
+		fs.Func("icon", "", config.SetIconPack)
+		fs.Func("icons", "", config.SetIconPack)
+
+		fs.StringVar(&config.IconsFile, "icons-file", "", "")
+		fs.BoolVar(&config.ASCII, "ascii", false, "")
+
+		fs.StringVar(&config.RulesFile, "rules", "", "")
+	}
+
+	if groups&LocationFlags != 0 {
+		fs.Func("lat", "", config.SetLatitude)
+		fs.Func("latitude", "", config.SetLatitude)
+		fs.Func("lon", "", config.SetLongitude)
+		fs.Func("longitude", "", config.SetLongitude)
+		fs.Func("elevation", "", config.SetElevation)
+		fs.Func("city", "", config.SetCity)
+		fs.Func("lunar-day", "", config.SetLunarDay)
+	}
+
+	if groups&OutputFlags != 0 {
+		fs.StringVar(&config.Output, "o", "-", "")
+		fs.StringVar(&config.Output, "out", "-", "")
+		fs.StringVar(&config.Output, "output", "-", "")
+	}
+
+	if groups&CalendarFlags != 0 {
+		fs.Func("f", "", config.SetFormat)
+		fs.Func("format", "", config.SetFormat)
+
+		fs.BoolVar(&config.ImageIcons, "image-icons", false, "")
+		fs.BoolVar(&config.UTCColumn, "utc-column", false, "")
+		fs.BoolVar(&config.IlluminationColumn, "illumination-column", false, "")
+		fs.BoolVar(&config.AgeColumn, "age-column", false, "")
+		fs.BoolVar(&config.LunarDayColumn, "lunar-day-column", false, "")
+		fs.BoolVar(&config.ElementColumn, "element-column", false, "")
+		fs.Func("element-zodiac", "", config.SetElementZodiac)
+		fs.BoolVar(&config.TUI, "tui", false, "")
+	}
+
+	return fs
+}
+
+// PrintOptions writes the help of the option groups of fs, after the
+// options of the command itself.
+func (fs *FlagSet) PrintOptions(w io.Writer, options string) {
+	fmt.Fprint(w, "supported options:\n", options)
+	if fs.groups&PeriodFlags != 0 {
+		fmt.Fprint(w, usagePeriod)
+	}
+	if fs.groups&SettingsFlags != 0 {
+		fmt.Fprint(w, usageSettings)
+	}
+	if fs.groups&VerdictFlags != 0 {
+		fmt.Fprint(w, usageVerdicts)
+	}
+	if fs.groups&LocationFlags != 0 {
+		fmt.Fprint(w, usageLocation)
+	}
+	if fs.groups&OutputFlags != 0 {
+		fmt.Fprint(w, usageOutput)
+	}
+	if fs.groups&CalendarFlags != 0 {
+		fmt.Fprint(w, usageCalendar)
+	}
+	fmt.Fprint(w, usageHelp)
+	if fs.groups&SettingsFlags != 0 {
+		fmt.Fprint(w, usagePrecedence)
+	}
+}
+
+// Parse parses args and completes the configuration from configuration
+// files and environment variables. It exits on invalid settings.
+func (fs *FlagSet) Parse(args []string) Config {
+	fs.FlagSet.Parse(args)
+
+	config := fs.config
+	if config.Month != 0 && config.Years != 0 {
+		fail(errors.New("cannot mix --month and --years"))
+	}
+	if fs.groups&SettingsFlags != 0 {
+		set := make(map[string]bool)
+		fs.Visit(func(f *flag.Flag) { set[f.Name] = true })
+
+		if err := config.applySettings(set); err != nil {
+			fail(err)
+		}
+
+		// TT is no civil time: it has no zones
+		if config.TimeScale == jd.TT {
+			if set["z"] || set["tz"] || set["timezone"] {
+				fail(errors.New("cannot mix --time-scale tt and --tz"))
+			}
+			config.TZ = time.UTC
+		}
+	}
+	if config.TZ == nil {
+		config.TZ = time.Local
+	}
+
+	// the conversions of times, the range check's first, follow the scale
+	jd.SetScale(config.TimeScale)
+
+	if fs.groups&PeriodFlags != 0 {
+		if err := jd.CheckRange(config.Range()); err != nil {
+			fail(err)
+		}
+	}
+
+	if fs.groups&LocationFlags != 0 {
+		if err := config.resolveLocation(); err != nil {
+			fail(err)
+		}
+	}
+
+	if fs.groups&VerdictFlags == 0 {
+		return *config
+	}
+
+	if err := config.ResolveIcons(); err != nil {
+		fail(err)
+	}
+
+	if config.RulesFile != "" {
+		var err error
+		config.Rules, err = rules.LoadFile(config.RulesFile)
+		if err != nil {
+			fail(err)
+		}
+	}
+
+	return *config
+}
+
+func fail(err error) {
+	fmt.Fprintln(os.Stderr, err)
+	os.Exit(2)
+}
@@ -0,0 +1,101 @@
+package element
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/mbolis/mogo/model"
+	"github.com/mbolis/mogo/sign"
+)
+
+// Element is the classical element of the sign the Moon is in, as used
+// by biodynamic calendars to tell root, flower, leaf and fruit days.
+type Element int
+
+const (
+	Earth  Element = iota // root days
+	Light                 // flower days
+	Water                 // leaf days
+	Warmth                // fruit days
+)
+
+// Of returns the element of the sign s: earth signs are Earth, air signs
+// Light, water signs Water and fire signs Warmth. Ophiuchus takes the
+// element of Scorpio, which biodynamic calendars stretch over it.
+func Of(s sign.Sign) Element {
+	switch s {
+	case sign.Taurus, sign.Virgo, sign.Capricorn:
+		return Earth
+	case sign.Gemini, sign.Libra, sign.Aquarius:
+		return Light
+	case sign.Cancer, sign.Scorpio, sign.Ophiuchus, sign.Pisces:
+		return Water
+	case sign.Aries, sign.Leo, sign.Sagittarius:
+		return Warmth
+	default:
+		panic(fmt.Sprintf("unknown sign: %d", s))
+	}
+}
+
+func (e Element) String() string {
+	switch e {
+	case Earth:
+		return "Earth"
+	case Light:
+		return "Light"
+	case Water:
+		return "Water"
+	case Warmth:
+		return "Warmth"
+	default:
+		panic(fmt.Sprintf("unknown element: %d", e))
+	}
+}
+
+// Organ returns the part of the plant favoured on the days of e.
+func (e Element) Organ() string {
+	switch e {
+	case Earth:
+		return "Root"
+	case Light:
+		return "Flower"
+	case Water:
+		return "Leaf"
+	case Warmth:
+		return "Fruit"
+	default:
+		panic(fmt.Sprintf("unknown element: %d", e))
+	}
+}
+
+// ForDay computes the elements of the day of d, with the signs divided by z.
+func ForDay(d time.Time, z sign.Zodiac) model.DailyValue[Element] {
+	return FromSigns(sign.ForDayIn(d, z))
+}
+
+// ForDays computes the elements of the days from start to end, which must
+// be midnights, with the signs divided by z.
+func ForDays(start, end time.Time, z sign.Zodiac) []model.DailyValue[Element] {
+	var dvs []model.DailyValue[Element]
+	for _, signs := range sign.ForDays(start, end, z) {
+		dvs = append(dvs, FromSigns(signs))
+	}
+	return dvs
+}
+
+// FromSigns returns the elements of the signs of a day. Adjacent signs
+// have different elements but for Scorpio and Ophiuchus, both Water: the
+// ingress into Ophiuchus is no change of element, the one out of it is.
+func FromSigns(signs model.DailyValue[sign.Sign]) (dv model.DailyValue[Element]) {
+	dv.Curr = Of(signs.Curr)
+	dv.Next = Of(signs.Next)
+
+	e := dv.Curr
+	for _, s := range signs.Events {
+		if Of(s.Value) != e {
+			e = Of(s.Value)
+			dv.Events = append(dv.Events, model.Event[Element]{Time: s.Time, Value: e})
+		}
+	}
+	return
+}
@@ -8,20 +8,41 @@ import (
 )
 
 func GenerateCSV(cfg config.Config, days []Day, out io.Writer) {
-	rows := [][]string{
-		{
-			T("Month"), T("Day"), T("Hour"), T("Phase"), "", T("Sign"), "",
-			T("Haircut"), T("Nails cut"), T("Epilation"), T("Facial cleansing"), T("Face mask"),
-		},
-	}
+	w := newCSVCalendar(cfg, out)
 	for _, d := range days {
-		for _, r := range d.Rows(cfg) {
-			rows = append(rows, r.Strings())
-		}
+		w.write(d)
+	}
+}
+
+// StreamCSV writes the rows of days as soon as they arrive.
+func StreamCSV(cfg config.Config, days <-chan Day, out io.Writer) {
+	w := newCSVCalendar(cfg, out)
+	for d := range days {
+		w.write(d)
+	}
+}
+
+type csvCalendar struct {
+	cfg config.Config
+	*csv.Writer
+}
+
+func newCSVCalendar(cfg config.Config, out io.Writer) csvCalendar {
+	w := csvCalendar{cfg, csv.NewWriter(out)}
+	w.flush(Header(cfg))
+	return w
+}
+
+func (w csvCalendar) write(d Day) {
+	var rows [][]string
+	for _, r := range d.Rows(w.cfg) {
+		rows = append(rows, r.Strings())
 	}
+	w.flush(rows...)
+}
 
-	csv := csv.NewWriter(out)
-	err := csv.WriteAll(rows)
+func (w csvCalendar) flush(rows ...[]string) {
+	err := w.WriteAll(rows)
 	if err != nil {
 		panic(err)
 	}
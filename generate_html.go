@@ -0,0 +1,76 @@
+package main
+
+import (
+	"html/template"
+	"io"
+	"strconv"
+
+	"github.com/mbolis/mogo/config"
+	tpl "github.com/mbolis/mogo/template"
+)
+
+type htmlDocument struct {
+	Lang        string
+	Title       string
+	Description string
+	Salon       []string
+	Header      []string
+	TextColumns []bool
+	Months      []htmlMonth
+}
+
+type htmlMonth struct {
+	Title string
+	Rows  []htmlRow
+}
+
+type htmlRow struct {
+	Odd   bool
+	Cells []string
+}
+
+func GenerateHTML(cfg config.Config, days []Day, out io.Writer) {
+	page, err := template.New("html").Parse(tpl.HTML())
+	if err != nil {
+		panic(err)
+	}
+
+	doc := htmlDocument{
+		Lang:        cfg.Lang.String(),
+		Title:       strconv.Itoa(cfg.Year),
+		Description: DocumentNote(cfg),
+		Header:      Header(cfg),
+	}
+	if name := cfg.Salon.Name; name != "" {
+		doc.Title = name + " – " + doc.Title
+	}
+	for _, line := range []string{cfg.Salon.Address, cfg.Salon.Phone, cfg.Salon.Email, cfg.Salon.Website} {
+		if line != "" {
+			doc.Salon = append(doc.Salon, line)
+		}
+	}
+
+	// month and day names, phases and signs are left-aligned
+	doc.TextColumns = make([]bool, len(doc.Header))
+	for _, i := range []int{0, 1, 4, 6} {
+		doc.TextColumns[i] = true
+	}
+
+	for i, d := range days {
+		if y, m, day := cfg.Calendar.Date(d.Time); len(doc.Months) == 0 || day == 1 {
+			doc.Months = append(doc.Months, htmlMonth{
+				Title: T("month."+m.String()[:3]) + " " + strconv.Itoa(y),
+			})
+		}
+		month := &doc.Months[len(doc.Months)-1]
+
+		for _, r := range d.Rows(cfg) {
+			month.Rows = append(month.Rows, htmlRow{i%2 == 0, r.Strings()})
+		}
+	}
+
+	err = page.Execute(out, doc)
+	if err != nil {
+		panic(err)
+	}
+}
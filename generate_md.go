@@ -0,0 +1,45 @@
+package main
+
+import (
+	"bufio"
+	"io"
+	"strings"
+
+	"github.com/mbolis/mogo/config"
+)
+
+var markdownEscaper = strings.NewReplacer(`\`, `\\`, `|`, `\|`, "\n", " ")
+
+func GenerateMarkdown(cfg config.Config, days []Day, out io.Writer) {
+	w := bufio.NewWriter(out)
+
+	header := Header(cfg)
+	writeMarkdownRow(w, header)
+
+	separator := make([]string, len(header))
+	for i := range separator {
+		separator[i] = "---"
+	}
+	writeMarkdownRow(w, separator)
+
+	for _, d := range days {
+		for _, r := range d.Rows(cfg) {
+			writeMarkdownRow(w, r.Strings())
+		}
+	}
+
+	err := w.Flush()
+	if err != nil {
+		panic(err)
+	}
+}
+
+func writeMarkdownRow(w *bufio.Writer, cells []string) {
+	w.WriteString("|")
+	for _, c := range cells {
+		w.WriteString(" ")
+		w.WriteString(markdownEscaper.Replace(c))
+		w.WriteString(" |")
+	}
+	w.WriteString("\n")
+}
@@ -3,8 +3,11 @@ package main
 import (
 	"io"
 	"strconv"
+	"time"
 
 	"github.com/mbolis/mogo/config"
+	"github.com/mbolis/mogo/icons"
+	"github.com/mbolis/mogo/model"
 	"github.com/mbolis/mogo/ods"
 )
 
@@ -15,21 +18,62 @@ func GenerateODS(cfg config.Config, days []Day, out io.Writer) {
 	}
 
 	header := doc.Row(0)
-	header.SetCellString(0, T("Day"))
-	header.SetCellString(1, T("Hour"))
-	header.SetCellString(2, T("Phase"))
-	header.SetCellString(3, T("Sign"))
-	header.SetCellString(4, T("Haircut"))
-	header.SetCellString(5, T("Nails cut"))
-	header.SetCellString(6, T("Epilation"))
-	header.SetCellString(7, T("Facial cleansing"))
-	header.SetCellString(8, T("Face mask"))
+	header.SetCellString(0, DateHeader(cfg, "Day"))
+	header.SetCellString(2, T("Hour"))
+	header.SetCellString(3, T("Phase"))
+	header.SetCellString(5, T("Sign"))
+	header.SetCellString(7, T("Haircut"))
+	header.SetCellString(8, T("Nails cut"))
+	header.SetCellString(9, T("Epilation"))
+	header.SetCellString(10, T("Facial cleansing"))
+	header.SetCellString(11, T("Face mask"))
+
+	extraColumns := ExtraColumns(cfg)
+	for i, c := range extraColumns {
+		header.SetCellStyle(12+i, header.CellStyle(10))
+		header.SetCellString(12+i, T(c.Header))
+	}
 
 	sourceRows := [2]*ods.Row{
 		doc.Row(2).Remove(),
 		doc.Row(1).Remove(),
 	}
 
+	// time cells off the standard offset get a style of their own, a copy
+	// of the one of the template followed by the offset
+	var timeStyles [2]string
+	timeFormat := ods.TimeFormat{Seconds: cfg.Precision == model.Second}
+	offsetStyle := func(i int, t time.Time) string {
+		format := timeFormat
+		format.Suffix = offsetLabel(t)
+		if format.Suffix == "" {
+			return ""
+		}
+		return doc.TimeCellStyle(timeStyles[i], format)
+	}
+
+	for i, sourceRow := range sourceRows {
+		timeStyles[i] = sourceRow.CellStyle(2)
+		if cfg.Precision == model.Second {
+			sourceRow.SetCellStyle(2, doc.TimeCellStyle(timeStyles[i], timeFormat))
+		}
+		for i, c := range extraColumns {
+			switch c.Kind {
+			case TimeCell:
+				sourceRow.SetCellStyle(12+i, sourceRow.CellStyle(2))
+			case IntegerCell:
+				sourceRow.SetCellStyle(12+i, doc.NumberCellStyle(sourceRow.CellStyle(4), ods.NumberFormat{}))
+			case NumberCell:
+				sourceRow.SetCellStyle(12+i, doc.NumberCellStyle(sourceRow.CellStyle(4), ods.NumberFormat{Decimals: 1}))
+			case PercentCell:
+				sourceRow.SetCellStyle(12+i, doc.NumberCellStyle(sourceRow.CellStyle(4), ods.NumberFormat{Percent: true}))
+			default:
+				sourceRow.SetCellStyle(12+i, sourceRow.CellStyle(4))
+			}
+		}
+	}
+
+	images := odsImages{doc}
 	prevRow := header
 
 	for i, d := range days {
@@ -40,25 +84,57 @@ func GenerateODS(cfg config.Config, days []Day, out io.Writer) {
 			currRow.InsertAfter(prevRow)
 			prevRow = currRow
 
-			currRow.SetCellDate(0, r.Date)
-			currRow.SetCellDate(1, r.Date)
+			if cfg.Calendar.InJulian(r.Date) {
+				// spreadsheet dates are Gregorian
+				month, day := r.DateStrings()
+				currRow.SetCellString(0, month)
+				currRow.SetCellString(1, day)
+			} else {
+				currRow.SetCellDate(0, r.Date)
+				currRow.SetCellDate(1, r.Date)
+			}
 			if !r.Time.IsZero() {
 				currRow.SetCellTime(2, r.Time)
+				if style := offsetStyle(i%2, r.Time); style != "" {
+					currRow.SetCellStyle(2, style)
+				}
 			}
 
 			phaseIcon, phaseName := r.PhaseText()
-			currRow.SetCellString(3, phaseIcon)
 			currRow.SetCellString(4, phaseName)
 
 			signIcon, signName := r.SignText()
-			currRow.SetCellString(5, signIcon)
 			currRow.SetCellString(6, signName)
 
-			currRow.SetCellString(7, r.HaircutIcon())
-			currRow.SetCellString(8, r.NailsCutIcon())
-			currRow.SetCellString(9, r.EpilationIcon())
-			currRow.SetCellString(10, r.FacialCleansingIcon())
-			currRow.SetCellString(11, r.FaceMaskIcon())
+			if cfg.ImageIcons {
+				if r.Phase >= 0 {
+					currRow.SetCellImages(3, images.add(icons.PhaseImage(r.Phase)), odsImageSize)
+				}
+				if r.Sign >= 0 {
+					currRow.SetCellImages(5, images.add(icons.SignImage(r.Sign)), odsImageSize)
+				}
+				for i, s := range r.Statuses() {
+					currRow.SetCellImages(7+i, images.add(icons.StatusImages(s)...), odsImageSize)
+				}
+			} else {
+				currRow.SetCellString(3, phaseIcon)
+				currRow.SetCellString(5, signIcon)
+
+				currRow.SetCellString(7, r.HaircutIcon())
+				currRow.SetCellString(8, r.NailsCutIcon())
+				currRow.SetCellString(9, r.EpilationIcon())
+				currRow.SetCellString(10, r.FacialCleansingIcon())
+				currRow.SetCellString(11, r.FaceMaskIcon())
+			}
+
+			for j, c := range extraColumns {
+				setExtraCell(currRow, 12+j, c, r)
+				if t, ok := c.Value(r).(time.Time); ok && c.Kind == TimeCell {
+					if style := offsetStyle(i%2, t); style != "" {
+						currRow.SetCellStyle(12+j, style)
+					}
+				}
+			}
 		}
 	}
 
@@ -67,8 +143,68 @@ func GenerateODS(cfg config.Config, days []Day, out io.Writer) {
 	// mark first row as header rows so LibreOffice repeats it on every page
 	doc.SetHeaderRows(1)
 
+	err = doc.SetLanguage(cfg.Lang)
+	if err != nil {
+		panic(err)
+	}
+
+	err = doc.SetDescription(DocumentNote(cfg))
+	if err != nil {
+		panic(err)
+	}
+
 	err = doc.Write(out)
 	if err != nil {
 		panic(err)
 	}
 }
+
+func setExtraCell(row *ods.Row, col int, c Column, r Row) {
+	switch v := c.Value(r).(type) {
+	case nil:
+	case time.Time:
+		switch c.Kind {
+		case TimeCell:
+			row.SetCellTime(col, v)
+		default:
+			row.SetCellDateTime(col, v)
+		}
+	case int:
+		row.SetCellFloat(col, float64(v))
+	case float64:
+		switch c.Kind {
+		case PercentCell:
+			row.SetCellPercentage(col, v)
+		default:
+			row.SetCellFloat(col, v)
+		}
+	case string:
+		row.SetCellString(col, v)
+	default:
+		row.SetCellString(col, c.String(r))
+	}
+}
+
+// odsImageSize is the side in centimeters of pictures anchored to cells.
+const odsImageSize = 0.38
+
+type odsImages struct {
+	doc *ods.Document
+}
+
+// add stores the SVG and PNG renditions of images in the document.
+func (images odsImages) add(imgs ...icons.Image) []ods.Image {
+	var refs []ods.Image
+	for _, img := range imgs {
+		svg, err := images.doc.AddImage(img.Name+".svg", "image/svg+xml", img.SVG)
+		if err != nil {
+			panic(err)
+		}
+		png, err := images.doc.AddImage(img.Name+".png", "image/png", img.PNG)
+		if err != nil {
+			panic(err)
+		}
+		refs = append(refs, ods.Image{Name: img.Name, Hrefs: []string{svg, png}})
+	}
+	return refs
+}
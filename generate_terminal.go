@@ -0,0 +1,108 @@
+package main
+
+import (
+	"bufio"
+	"io"
+	"strings"
+
+	"github.com/mattn/go-runewidth"
+	"github.com/mbolis/mogo/config"
+	"github.com/mbolis/mogo/status"
+)
+
+const (
+	ansiReset  = "\x1b[0m"
+	ansiBold   = "\x1b[1m"
+	ansiFaint  = "\x1b[2m"
+	ansiRed    = "\x1b[31m"
+	ansiGreen  = "\x1b[32m"
+	ansiYellow = "\x1b[33m"
+)
+
+// statusColumn is the index of the first verdict column in Row.Strings.
+const statusColumn = 7
+
+func statusColor(s status.Status) string {
+	switch {
+	case s == status.Warning:
+		return ansiYellow
+	case s <= status.VeryNegative:
+		return ansiBold + ansiRed
+	case s < 0:
+		return ansiRed
+	case s >= status.VeryPositive:
+		return ansiBold + ansiGreen
+	case s > 0:
+		return ansiGreen
+	default:
+		return ""
+	}
+}
+
+// GenerateTerminal writes the calendar as a table aligned for display
+// in a terminal, coloring verdicts with ANSI escapes if color is set.
+func GenerateTerminal(cfg config.Config, days []Day, out io.Writer, color bool) {
+	header := Header(cfg)
+
+	var rows [][]string
+	var colors [][]string
+	for _, d := range days {
+		for _, r := range d.Rows(cfg) {
+			rows = append(rows, r.Strings())
+
+			rowColors := make([]string, len(header))
+			for i, s := range r.Statuses() {
+				rowColors[statusColumn+i] = statusColor(s)
+			}
+			colors = append(colors, rowColors)
+		}
+	}
+
+	widths := make([]int, len(header))
+	for _, row := range append([][]string{header}, rows...) {
+		for i, cell := range row {
+			widths[i] = max(widths[i], runewidth.StringWidth(cell))
+		}
+	}
+
+	w := bufio.NewWriter(out)
+
+	headerColors := make([]string, len(header))
+	for i := range headerColors {
+		headerColors[i] = ansiBold
+	}
+	writeTerminalRow(w, header, headerColors, widths, color)
+
+	var prevDate string
+	for i, row := range rows {
+		// repeated month and day of multi-event days are faded out
+		date := row[0] + row[1]
+		if date == prevDate && color {
+			colors[i][0], colors[i][1] = ansiFaint, ansiFaint
+		}
+		prevDate = date
+
+		writeTerminalRow(w, row, colors[i], widths, color)
+	}
+
+	err := w.Flush()
+	if err != nil {
+		panic(err)
+	}
+}
+
+func writeTerminalRow(w *bufio.Writer, cells, colors []string, widths []int, color bool) {
+	for i, cell := range cells {
+		if i > 0 {
+			w.WriteString(" ")
+		}
+
+		padding := strings.Repeat(" ", widths[i]-runewidth.StringWidth(cell))
+		if color && colors[i] != "" && cell != "" {
+			w.WriteString(colors[i] + cell + ansiReset + padding)
+		} else {
+			w.WriteString(cell + padding)
+		}
+	}
+	w.WriteString("\n")
+}
@@ -0,0 +1,256 @@
+package main
+
+import (
+	"archive/zip"
+	"bytes"
+	"flag"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/beevik/etree"
+	"github.com/mbolis/mogo/config"
+	"github.com/mbolis/mogo/element"
+	"github.com/mbolis/mogo/i18n"
+	"github.com/mbolis/mogo/icons"
+	"github.com/mbolis/mogo/lunarday"
+	"github.com/mbolis/mogo/model"
+	"github.com/mbolis/mogo/phase"
+	"github.com/mbolis/mogo/sign"
+	"github.com/xuri/excelize/v2"
+	"golang.org/x/text/language"
+)
+
+var update = flag.Bool("update", false, "rewrite the golden files in testdata")
+
+// testCalendar returns a week of days with every combination of phase and
+// sign events, fixed so that the output does not depend on the ephemeris.
+func testCalendar() (config.Config, []Day) {
+	i18n.SetLang(language.English)
+
+	tz := time.FixedZone("CET", 3600)
+	cfg := config.Config{
+		Year:           2024,
+		Month:          time.March,
+		TZ:             tz,
+		Icons:          icons.Arrows,
+		Lang:           language.English,
+		UTCColumn:      true,
+		LunarDayColumn: true,
+		ElementColumn:  true,
+	}
+
+	at := func(day, hour, min int) time.Time {
+		return time.Date(2024, time.March, day, hour, min, 0, 0, tz)
+	}
+	phases := func(curr, next phase.Phase, events ...model.Event[phase.Phase]) model.DailyValue[phase.Phase] {
+		return model.DailyValue[phase.Phase]{Curr: curr, Next: next, Events: events}
+	}
+	signs := func(curr, next sign.Sign, events ...model.Event[sign.Sign]) model.DailyValue[sign.Sign] {
+		return model.DailyValue[sign.Sign]{Curr: curr, Next: next, Events: events}
+	}
+
+	days := []Day{
+		{
+			Time:  at(8, 0, 0),
+			Phase: phases(phase.Waning3, phase.Waning3),
+			Sign:  signs(sign.Aquarius, sign.Aquarius),
+		},
+		{
+			Time:  at(9, 0, 0),
+			Phase: phases(phase.Waning3, phase.Waning3),
+			Sign:  signs(sign.Aquarius, sign.Pisces, model.Event[sign.Sign]{Time: at(9, 15, 40), Value: sign.Pisces}),
+		},
+		{
+			Time:  at(10, 0, 0),
+			Phase: phases(phase.Waning3, phase.Waxing1, model.Event[phase.Phase]{Time: at(10, 10, 0), Value: phase.New}),
+			Sign:  signs(sign.Pisces, sign.Pisces),
+		},
+		{
+			Time:  at(11, 0, 0),
+			Phase: phases(phase.Waxing1, phase.Waxing1),
+			Sign:  signs(sign.Pisces, sign.Aries, model.Event[sign.Sign]{Time: at(11, 3, 5), Value: sign.Aries}),
+		},
+		{
+			Time:  at(12, 0, 0),
+			Phase: phases(phase.Waxing1, phase.Waxing2, model.Event[phase.Phase]{Time: at(12, 8, 0), Value: phase.Waxing2}),
+			Sign:  signs(sign.Aries, sign.Taurus, model.Event[sign.Sign]{Time: at(12, 21, 30), Value: sign.Taurus}),
+		},
+		{
+			Time:  at(13, 0, 0),
+			Phase: phases(phase.Waxing2, phase.Full, model.Event[phase.Phase]{Time: at(13, 18, 0), Value: phase.Full}),
+			Sign:  signs(sign.Taurus, sign.Gemini, model.Event[sign.Sign]{Time: at(13, 6, 45), Value: sign.Gemini}),
+		},
+		{
+			Time:  at(14, 0, 0),
+			Phase: phases(phase.Full, phase.Waning1, model.Event[phase.Phase]{Time: at(14, 12, 0), Value: phase.Waning1}),
+			Sign:  signs(sign.Gemini, sign.Cancer, model.Event[sign.Sign]{Time: at(14, 12, 0), Value: sign.Cancer}),
+		},
+	}
+
+	for i := range days {
+		d := &days[i]
+		d.LunarDay = lunarday.Day{Start: i + 1, Changes: []model.Event[int]{{Time: d.Time.Add(9 * time.Hour), Value: i + 2}}}
+		d.Element = element.FromSigns(d.Sign)
+	}
+	return cfg, days
+}
+
+// checkGolden compares got with the golden file, or rewrites it with -update.
+func checkGolden(t *testing.T, name string, got []byte, equal func(t *testing.T, got, want []byte)) {
+	t.Helper()
+
+	filename := filepath.Join("testdata", name)
+	if *update {
+		if err := os.MkdirAll("testdata", 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filename, got, 0o644); err != nil {
+			t.Fatal(err)
+		}
+		return
+	}
+
+	want, err := os.ReadFile(filename)
+	if err != nil {
+		t.Fatalf("%v (run with -update to create it)", err)
+	}
+	equal(t, got, want)
+}
+
+func TestGenerateCSV(t *testing.T) {
+	cfg, days := testCalendar()
+
+	var out bytes.Buffer
+	GenerateCSV(cfg, days, &out)
+
+	checkGolden(t, "calendar.csv", out.Bytes(), func(t *testing.T, got, want []byte) {
+		if !bytes.Equal(got, want) {
+			t.Errorf("CSV differs from the golden file:\n%s", got)
+		}
+	})
+}
+
+func TestGenerateXLSX(t *testing.T) {
+	cfg, days := testCalendar()
+
+	var out bytes.Buffer
+	GenerateXLSX(cfg, days, &out)
+
+	checkGolden(t, "calendar.xlsx", out.Bytes(), compareXLSX)
+}
+
+// compareXLSX compares the sheets cell by cell, values and styles.
+func compareXLSX(t *testing.T, got, want []byte) {
+	gotFile, err := excelize.OpenReader(bytes.NewReader(got))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer gotFile.Close()
+	wantFile, err := excelize.OpenReader(bytes.NewReader(want))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer wantFile.Close()
+
+	if g, w := gotFile.GetSheetList(), wantFile.GetSheetList(); !reflect.DeepEqual(g, w) {
+		t.Fatalf("got sheets %v, want %v", g, w)
+	}
+
+	for _, sheet := range wantFile.GetSheetList() {
+		gotRows, err := gotFile.GetRows(sheet, excelize.Options{RawCellValue: true})
+		if err != nil {
+			t.Fatal(err)
+		}
+		wantRows, err := wantFile.GetRows(sheet, excelize.Options{RawCellValue: true})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(gotRows) != len(wantRows) {
+			t.Errorf("%s: got %d rows, want %d", sheet, len(gotRows), len(wantRows))
+		}
+
+		for r := 0; r < min(len(gotRows), len(wantRows)); r++ {
+			cols := max(len(gotRows[r]), len(wantRows[r]))
+			for c := 0; c < cols; c++ {
+				cell := cellName(r+1, c)
+				if g, w := cellAt(gotRows[r], c), cellAt(wantRows[r], c); g != w {
+					t.Errorf("%s!%s: got %q, want %q", sheet, cell, g, w)
+				}
+				if g, w := cellStyle(t, gotFile, sheet, cell), cellStyle(t, wantFile, sheet, cell); !reflect.DeepEqual(g, w) {
+					t.Errorf("%s!%s: got style %+v, want %+v", sheet, cell, g, w)
+				}
+			}
+		}
+	}
+}
+
+func cellAt(row []string, c int) string {
+	if c < len(row) {
+		return row[c]
+	}
+	return ""
+}
+
+func cellStyle(t *testing.T, f *excelize.File, sheet, cell string) *excelize.Style {
+	id, err := f.GetCellStyle(sheet, cell)
+	if err != nil {
+		t.Fatal(err)
+	}
+	style, err := f.GetStyle(id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return style
+}
+
+func TestGenerateODS(t *testing.T) {
+	cfg, days := testCalendar()
+
+	var out bytes.Buffer
+	GenerateODS(cfg, days, &out)
+
+	content := normalizeXML(t, zipEntry(t, out.Bytes(), "content.xml"))
+	checkGolden(t, "calendar.ods.content.xml", content, func(t *testing.T, got, want []byte) {
+		if !bytes.Equal(got, want) {
+			t.Errorf("content.xml differs from the golden file:\n%s", got)
+		}
+	})
+}
+
+func zipEntry(t *testing.T, data []byte, name string) []byte {
+	r, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	f, err := r.Open(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	entry, err := io.ReadAll(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return entry
+}
+
+// normalizeXML indents the document, so that the golden file can be
+// read and diffed.
+func normalizeXML(t *testing.T, data []byte) []byte {
+	doc := etree.NewDocument()
+	if err := doc.ReadFromBytes(data); err != nil {
+		t.Fatal(err)
+	}
+	doc.Indent(2)
+
+	out, err := doc.WriteToBytes()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return out
+}
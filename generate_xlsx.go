@@ -1,11 +1,13 @@
 package main
 
 import (
-	"fmt"
 	"io"
 	"strconv"
+	"time"
 
 	"github.com/mbolis/mogo/config"
+	"github.com/mbolis/mogo/icons"
+	"github.com/mbolis/mogo/model"
 	"github.com/mbolis/mogo/template"
 	"github.com/xuri/excelize/v2"
 )
@@ -17,7 +19,7 @@ func GenerateXLSX(cfg config.Config, days []Day, out io.Writer) {
 	}
 	defer tpl.Close()
 
-	tpl.SetCellStr("Sheet1", "A1", T("Month"))
+	tpl.SetCellStr("Sheet1", "A1", DateHeader(cfg, "Month"))
 	tpl.SetCellStr("Sheet1", "B1", T("Day"))
 	tpl.SetCellStr("Sheet1", "C1", T("Hour"))
 	tpl.SetCellStr("Sheet1", "D1", T("Phase"))
@@ -28,6 +30,38 @@ func GenerateXLSX(cfg config.Config, days []Day, out io.Writer) {
 	tpl.SetCellStr("Sheet1", "K1", T("Facial cleansing"))
 	tpl.SetCellStr("Sheet1", "L1", T("Face mask"))
 
+	extraColumns := ExtraColumns(cfg)
+	for i, c := range extraColumns {
+		mustCopyCellStyle(tpl, "Sheet1", 1, 7, 12+i, "")
+		mustSetCellStr(tpl, "Sheet1", 1, 12+i, T(c.Header))
+	}
+
+	// number formats are set on the source rows, so that every duplicate
+	// carries locale-aware formats whatever the template says
+	for _, sourceRow := range []int{2, 3} {
+		mustCopyCellStyle(tpl, "Sheet1", sourceRow, 0, 0, T("format.month"))
+		mustCopyCellStyle(tpl, "Sheet1", sourceRow, 1, 1, T("format.day"))
+		mustCopyCellStyle(tpl, "Sheet1", sourceRow, 2, 2, xlsxTimeFormat(cfg, "format.time"))
+
+		for i, c := range extraColumns {
+			switch c.Kind {
+			case TimeCell:
+				mustCopyCellStyle(tpl, "Sheet1", sourceRow, 2, 12+i, xlsxTimeFormat(cfg, "format.time"))
+			case DateTimeCell:
+				mustCopyCellStyle(tpl, "Sheet1", sourceRow, 2, 12+i, xlsxTimeFormat(cfg, "format.datetime"))
+			case IntegerCell:
+				mustCopyCellStyle(tpl, "Sheet1", sourceRow, 4, 12+i, "0")
+			case NumberCell:
+				mustCopyCellStyle(tpl, "Sheet1", sourceRow, 4, 12+i, "0.0")
+			case PercentCell:
+				mustCopyCellStyle(tpl, "Sheet1", sourceRow, 4, 12+i, "0%")
+			default:
+				mustCopyCellStyle(tpl, "Sheet1", sourceRow, 4, 12+i, "")
+			}
+		}
+	}
+
+	offsetStyles := make(xlsxOffsetStyles)
 	appendRowIndex := 4
 	for i, d := range days {
 		sourceRow := 3 - i%2 // odd=2 even=3
@@ -35,25 +69,50 @@ func GenerateXLSX(cfg config.Config, days []Day, out io.Writer) {
 		for _, r := range d.Rows(cfg) {
 			mustDuplicateRowTo(tpl, "Sheet1", sourceRow, appendRowIndex)
 
-			mustSetCellValue(tpl, "Sheet1", appendRowIndex, 0, r.Date)
-			mustSetCellValue(tpl, "Sheet1", appendRowIndex, 1, r.Date)
+			if cfg.Calendar.InJulian(r.Date) {
+				// spreadsheet dates are Gregorian
+				month, day := r.DateStrings()
+				mustSetCellStr(tpl, "Sheet1", appendRowIndex, 0, month)
+				mustSetCellStr(tpl, "Sheet1", appendRowIndex, 1, day)
+			} else {
+				mustSetCellValue(tpl, "Sheet1", appendRowIndex, 0, r.Date)
+				mustSetCellValue(tpl, "Sheet1", appendRowIndex, 1, r.Date)
+			}
 			if !r.Time.IsZero() {
-				mustSetCellValue(tpl, "Sheet1", appendRowIndex, 2, r.Time)
+				mustSetCellValue(tpl, "Sheet1", appendRowIndex, 2, excelTimeOfDay(r.Time))
+				offsetStyles.mustApply(tpl, "Sheet1", appendRowIndex, 2, r.Time)
 			}
 
 			phaseIcon, phaseName := r.PhaseText()
-			mustSetCellStr(tpl, "Sheet1", appendRowIndex, 3, phaseIcon)
 			mustSetCellStr(tpl, "Sheet1", appendRowIndex, 4, phaseName)
 
 			signIcon, signName := r.SignText()
-			mustSetCellStr(tpl, "Sheet1", appendRowIndex, 5, signIcon)
 			mustSetCellStr(tpl, "Sheet1", appendRowIndex, 6, signName)
 
-			mustSetCellStr(tpl, "Sheet1", appendRowIndex, 7, r.HaircutIcon())
-			mustSetCellStr(tpl, "Sheet1", appendRowIndex, 8, r.NailsCutIcon())
-			mustSetCellStr(tpl, "Sheet1", appendRowIndex, 9, r.EpilationIcon())
-			mustSetCellStr(tpl, "Sheet1", appendRowIndex, 10, r.FacialCleansingIcon())
-			mustSetCellStr(tpl, "Sheet1", appendRowIndex, 11, r.FaceMaskIcon())
+			if cfg.ImageIcons {
+				if r.Phase >= 0 {
+					mustAddImages(tpl, "Sheet1", appendRowIndex, 3, icons.PhaseImage(r.Phase))
+				}
+				if r.Sign >= 0 {
+					mustAddImages(tpl, "Sheet1", appendRowIndex, 5, icons.SignImage(r.Sign))
+				}
+				for i, s := range r.Statuses() {
+					mustAddImages(tpl, "Sheet1", appendRowIndex, 7+i, icons.StatusImages(s)...)
+				}
+			} else {
+				mustSetCellStr(tpl, "Sheet1", appendRowIndex, 3, phaseIcon)
+				mustSetCellStr(tpl, "Sheet1", appendRowIndex, 5, signIcon)
+
+				mustSetCellStr(tpl, "Sheet1", appendRowIndex, 7, r.HaircutIcon())
+				mustSetCellStr(tpl, "Sheet1", appendRowIndex, 8, r.NailsCutIcon())
+				mustSetCellStr(tpl, "Sheet1", appendRowIndex, 9, r.EpilationIcon())
+				mustSetCellStr(tpl, "Sheet1", appendRowIndex, 10, r.FacialCleansingIcon())
+				mustSetCellStr(tpl, "Sheet1", appendRowIndex, 11, r.FaceMaskIcon())
+			}
+
+			for i, c := range extraColumns {
+				mustSetExtraCell(tpl, "Sheet1", appendRowIndex, 12+i, c, r, offsetStyles)
+			}
 
 			appendRowIndex++
 		}
@@ -64,12 +123,138 @@ func GenerateXLSX(cfg config.Config, days []Day, out io.Writer) {
 
 	mustSetSheetName(tpl, "Sheet1", strconv.Itoa(cfg.Year))
 
+	props := &excelize.DocProperties{Description: DocumentNote(cfg)}
+	if cfg.Salon.Name != "" {
+		props.Title = cfg.Salon.Name + " – " + strconv.Itoa(cfg.Year)
+		props.Creator = cfg.Salon.Name
+	}
+	err = tpl.SetDocProps(props)
+	if err != nil {
+		panic(err)
+	}
+
 	err = tpl.Write(out)
 	if err != nil {
 		panic(err)
 	}
 }
 
+// xlsxTimeFormat returns the localized number format of times, with
+// seconds if the precision asks for them.
+func xlsxTimeFormat(cfg config.Config, key string) string {
+	format := T(key)
+	if cfg.Precision == model.Second {
+		format += ":ss"
+	}
+	return format
+}
+
+// excelTimeOfDay returns the local time of day as a fraction of a day,
+// which is how spreadsheets represent times without a date.
+func excelTimeOfDay(t time.Time) float64 {
+	seconds := t.Hour()*3600 + t.Minute()*60 + t.Second()
+	return float64(seconds) / (24 * 60 * 60)
+}
+
+func mustSetExtraCell(tpl *excelize.File, sheet string, row, col int, c Column, r Row, offsetStyles xlsxOffsetStyles) {
+	switch v := c.Value(r).(type) {
+	case nil:
+	case time.Time:
+		switch c.Kind {
+		case TimeCell:
+			mustSetCellValue(tpl, sheet, row, col, excelTimeOfDay(v))
+			offsetStyles.mustApply(tpl, sheet, row, col, v)
+		default:
+			mustSetCellValue(tpl, sheet, row, col, v)
+		}
+	case string:
+		mustSetCellStr(tpl, sheet, row, col, v)
+	default:
+		mustSetCellValue(tpl, sheet, row, col, v)
+	}
+}
+
+// xlsxOffsetStyles are the styles of time cells followed by their UTC
+// offset, by the style they are a copy of and the offset.
+type xlsxOffsetStyles map[xlsxOffsetStyle]int
+
+type xlsxOffsetStyle struct {
+	base   int
+	offset string
+}
+
+// mustApply appends the UTC offset of t to the number format of the cell,
+// as clock does, if it is not the standard one.
+func (styles xlsxOffsetStyles) mustApply(tpl *excelize.File, sheet string, row, col int, t time.Time) {
+	offset := offsetLabel(t)
+	if offset == "" {
+		return
+	}
+
+	base, err := tpl.GetCellStyle(sheet, cellName(row, col))
+	if err != nil {
+		panic(err)
+	}
+	key := xlsxOffsetStyle{base, offset}
+	id, ok := styles[key]
+	if !ok {
+		style, err := tpl.GetStyle(base)
+		if err != nil {
+			panic(err)
+		}
+		numFmt := `"` + offset + `"`
+		if style.CustomNumFmt != nil {
+			numFmt = *style.CustomNumFmt + " " + numFmt
+		}
+		style.NumFmt = 0
+		style.CustomNumFmt = &numFmt
+
+		id, err = tpl.NewStyle(style)
+		if err != nil {
+			panic(err)
+		}
+		styles[key] = id
+	}
+
+	err = tpl.SetCellStyle(sheet, cellName(row, col), cellName(row, col), id)
+	if err != nil {
+		panic(err)
+	}
+}
+
+// xlsxImagePixels is the side of pictures anchored to cells,
+// small enough to fit in the template rows.
+const xlsxImagePixels = 14
+
+func mustAddImages(tpl *excelize.File, sheet string, row, col int, images ...icons.Image) {
+	scale := float64(xlsxImagePixels) / icons.ImageSize
+	for i, img := range images {
+		err := tpl.AddPictureFromBytes(sheet, cellName(row, col), &excelize.Picture{
+			Extension: ".png",
+			File:      img.PNG,
+			Format: &excelize.GraphicOptions{
+				AltText:     img.Name,
+				ScaleX:      scale,
+				ScaleY:      scale,
+				OffsetX:     2 + i*xlsxImagePixels,
+				OffsetY:     1,
+				Positioning: "oneCell",
+			},
+		})
+		if err != nil {
+			panic(err)
+		}
+	}
+}
+
+func cellName(row, col int) string {
+	name, err := excelize.CoordinatesToCellName(col+1, row)
+	if err != nil {
+		panic(err)
+	}
+	return name
+}
+
 func mustDuplicateRowTo(tpl *excelize.File, sheet string, src, dst int) {
 	err := tpl.DuplicateRowTo(sheet, src, dst)
 	if err != nil {
@@ -78,14 +263,42 @@ func mustDuplicateRowTo(tpl *excelize.File, sheet string, src, dst int) {
 }
 
 func mustSetCellValue(tpl *excelize.File, sheet string, row, col int, value any) {
-	err := tpl.SetCellValue(sheet, fmt.Sprintf("%c%d", 'A'+col, row), value)
+	err := tpl.SetCellValue(sheet, cellName(row, col), value)
 	if err != nil {
 		panic(err)
 	}
 }
 
 func mustSetCellStr(tpl *excelize.File, sheet string, row, col int, value string) {
-	err := tpl.SetCellStr(sheet, fmt.Sprintf("%c%d", 'A'+col, row), value)
+	err := tpl.SetCellStr(sheet, cellName(row, col), value)
+	if err != nil {
+		panic(err)
+	}
+}
+
+// mustCopyCellStyle applies the style of cell (row, src) to cell (row, dst),
+// replacing its number format with numFmt unless empty.
+func mustCopyCellStyle(tpl *excelize.File, sheet string, row, src, dst int, numFmt string) {
+	id, err := tpl.GetCellStyle(sheet, cellName(row, src))
+	if err != nil {
+		panic(err)
+	}
+
+	if numFmt != "" {
+		style, err := tpl.GetStyle(id)
+		if err != nil {
+			panic(err)
+		}
+		style.NumFmt = 0
+		style.CustomNumFmt = &numFmt
+
+		id, err = tpl.NewStyle(style)
+		if err != nil {
+			panic(err)
+		}
+	}
+
+	err = tpl.SetCellStyle(sheet, cellName(row, dst), cellName(row, dst), id)
 	if err != nil {
 		panic(err)
 	}
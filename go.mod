@@ -3,24 +3,31 @@ module github.com/mbolis/mogo
 go 1.22.1
 
 require (
+	github.com/BurntSushi/toml v1.4.0
+	github.com/beevik/etree v1.4.1
+	github.com/jeandeaual/go-locale v0.0.0-20240223122105-ce5225dcaa49
+	github.com/mattn/go-runewidth v0.0.15
 	github.com/mshafiee/swephgo v1.1.0
+	github.com/nicksnyder/go-i18n/v2 v2.4.0
+	github.com/psanford/memfs v0.0.0-20230130182539-4dbf7e3e865e
 	github.com/soniakeys/meeus/v3 v3.0.1
+	github.com/srwiley/oksvg v0.0.0-20221011165216-be6e8873101c
+	github.com/srwiley/rasterx v0.0.0-20220730225603-2ab79fcdd4ef
+	github.com/xuri/excelize/v2 v2.8.1
+	golang.org/x/term v0.17.0
+	golang.org/x/text v0.14.0
 )
 
 require (
-	github.com/beevik/etree v1.4.1 // indirect
-	github.com/jeandeaual/go-locale v0.0.0-20240223122105-ce5225dcaa49 // indirect
 	github.com/mohae/deepcopy v0.0.0-20170929034955-c48cc78d4826 // indirect
-	github.com/nicksnyder/go-i18n/v2 v2.4.0 // indirect
-	github.com/psanford/memfs v0.0.0-20230130182539-4dbf7e3e865e // indirect
 	github.com/richardlehane/mscfb v1.0.4 // indirect
 	github.com/richardlehane/msoleps v1.0.3 // indirect
+	github.com/rivo/uniseg v0.2.0 // indirect
 	github.com/soniakeys/unit v1.0.0 // indirect
 	github.com/xuri/efp v0.0.0-20231025114914-d1ff6096ae53 // indirect
-	github.com/xuri/excelize/v2 v2.8.1 // indirect
 	github.com/xuri/nfp v0.0.0-20230919160717-d98342af3f05 // indirect
 	golang.org/x/crypto v0.19.0 // indirect
+	golang.org/x/image v0.14.0 // indirect
 	golang.org/x/net v0.21.0 // indirect
 	golang.org/x/sys v0.17.0 // indirect
-	golang.org/x/text v0.14.0 // indirect
 )
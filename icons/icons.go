@@ -4,120 +4,187 @@ import (
 	"fmt"
 	"strings"
 
+	"github.com/mbolis/mogo/element"
 	"github.com/mbolis/mogo/phase"
 	"github.com/mbolis/mogo/sign"
 	"github.com/mbolis/mogo/status"
 )
 
-type Style int
+// Style is a pack of icons used for indicators in the output.
+// Phases, Signs and Elements override the default moon, zodiac and
+// biodynamic symbols.
+type Style struct {
+	Name     string
+	Positive string
+	Negative string
+	Warning  string
+	Phases   map[phase.Phase]string
+	Signs    map[sign.Sign]string
+	Elements map[element.Element]string
 
-const (
-	Arrows Style = iota
-	Thumbs
-	Semaphore
-)
+	// ASCII is used instead of the pack when plain text is required
+	ASCII *Style
+}
 
-func (style Style) positive() rune {
-	switch style {
-	case Arrows:
-		return '🔼'
-	case Thumbs:
-		return '👍'
-	case Semaphore:
-		return '🟢'
-	default:
-		panic("unrecognized style")
+var (
+	Arrows    = Style{Name: "arrows", Positive: "🔼", Negative: "🔽", Warning: "🔁", ASCII: &asciiArrows}
+	Thumbs    = Style{Name: "thumbs", Positive: "👍", Negative: "👎", Warning: "✋", ASCII: &ASCII}
+	Semaphore = Style{Name: "semaphore", Positive: "🟢", Negative: "🔴", Warning: "🟡", ASCII: &ASCII}
+
+	ASCII = Style{
+		Name:     "ascii",
+		Positive: "+",
+		Negative: "-",
+		Warning:  "!",
+		Phases: map[phase.Phase]string{
+			phase.New:     "o",
+			phase.Waxing1: ")",
+			phase.Waxing2: "D",
+			phase.Waxing3: "O)",
+			phase.Full:    "O",
+			phase.Waning1: "(O",
+			phase.Waning2: "C",
+			phase.Waning3: "(",
+		},
+		Signs: map[sign.Sign]string{
+			sign.Aries:       "Ar",
+			sign.Taurus:      "Ta",
+			sign.Gemini:      "Ge",
+			sign.Cancer:      "Cn",
+			sign.Leo:         "Le",
+			sign.Virgo:       "Vi",
+			sign.Libra:       "Li",
+			sign.Scorpio:     "Sc",
+			sign.Sagittarius: "Sg",
+			sign.Capricorn:   "Cp",
+			sign.Aquarius:    "Aq",
+			sign.Pisces:      "Pi",
+			sign.Ophiuchus:   "Op",
+		},
+		Elements: map[element.Element]string{
+			element.Earth:  "Rt",
+			element.Light:  "Fl",
+			element.Water:  "Lf",
+			element.Warmth: "Fr",
+		},
 	}
-}
+	asciiArrows = Style{Name: "arrows", Positive: "^", Negative: "v", Warning: "~", Phases: ASCII.Phases, Signs: ASCII.Signs, Elements: ASCII.Elements}
+)
 
-func (style Style) negative() rune {
-	switch style {
-	case Arrows:
-		return '🔽'
-	case Thumbs:
-		return '👎'
-	case Semaphore:
-		return '🔴'
-	default:
-		panic("unrecognized style")
+// Builtin returns the packs which are always available, by name.
+func Builtin() map[string]Style {
+	return map[string]Style{
+		Arrows.Name:    Arrows,
+		Thumbs.Name:    Thumbs,
+		Semaphore.Name: Semaphore,
+		ASCII.Name:     ASCII,
 	}
 }
 
-func (style Style) warning() rune {
-	switch style {
-	case Arrows:
-		return '🔁'
-	case Thumbs:
-		return '✋'
-	case Semaphore:
-		return '🟡'
+// PlainText returns the ASCII variant of the pack, or the default
+// ASCII pack if it does not define one.
+func (style Style) PlainText() Style {
+	switch {
+	case style.ASCII != nil:
+		return *style.ASCII
+	case style.Name == ASCII.Name:
+		return style
 	default:
-		panic("unrecognized style")
+		return ASCII
 	}
 }
 
 func (style Style) Status(s status.Status) string {
 	switch {
 	case s == status.Warning:
-		return string(style.warning())
+		return style.Warning
 	case s < 0:
-		return strings.Repeat(string(style.negative()), -int(s))
+		return strings.Repeat(style.Negative, -int(s))
 	case s > 0:
-		return strings.Repeat(string(style.positive()), int(s))
+		return strings.Repeat(style.Positive, int(s))
 	default:
 		return ""
 	}
 }
 
-func (Style) Sign(s sign.Sign) rune {
+func (style Style) Sign(s sign.Sign) string {
+	if icon, ok := style.Signs[s]; ok {
+		return icon
+	}
+
 	switch s {
 	case sign.Aries:
-		return '♈'
+		return "♈"
 	case sign.Taurus:
-		return '♉'
+		return "♉"
 	case sign.Gemini:
-		return '♊'
+		return "♊"
 	case sign.Cancer:
-		return '♋'
+		return "♋"
 	case sign.Leo:
-		return '♌'
+		return "♌"
 	case sign.Virgo:
-		return '♍'
+		return "♍"
 	case sign.Libra:
-		return '♎'
+		return "♎"
 	case sign.Scorpio:
-		return '♏'
+		return "♏"
 	case sign.Sagittarius:
-		return '♐'
+		return "♐"
 	case sign.Capricorn:
-		return '♑'
+		return "♑"
 	case sign.Aquarius:
-		return '♒'
+		return "♒"
 	case sign.Pisces:
-		return '♓'
+		return "♓"
+	case sign.Ophiuchus:
+		return "⛎"
 	default:
 		panic(fmt.Sprintf("unknown sign: %d", s))
 	}
 }
 
-func (Style) Phase(ph phase.Phase) rune {
+func (style Style) Element(e element.Element) string {
+	if icon, ok := style.Elements[e]; ok {
+		return icon
+	}
+
+	switch e {
+	case element.Earth:
+		return "🥕"
+	case element.Light:
+		return "🌸"
+	case element.Water:
+		return "🍃"
+	case element.Warmth:
+		return "🍎"
+	default:
+		panic(fmt.Sprintf("unknown element: %d", e))
+	}
+}
+
+func (style Style) Phase(ph phase.Phase) string {
+	if icon, ok := style.Phases[ph]; ok {
+		return icon
+	}
+
 	switch ph {
 	case phase.New:
-		return '🌑'
+		return "🌑"
 	case phase.Waxing1:
-		return '🌒'
+		return "🌒"
 	case phase.Waxing2:
-		return '🌓'
+		return "🌓"
 	case phase.Waxing3:
-		return '🌔'
+		return "🌔"
 	case phase.Full:
-		return '🌕'
+		return "🌕"
 	case phase.Waning1:
-		return '🌖'
+		return "🌖"
 	case phase.Waning2:
-		return '🌗'
+		return "🌗"
 	case phase.Waning3:
-		return '🌘'
+		return "🌘"
 	}
 	panic("impossible moon phase")
 }
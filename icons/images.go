@@ -0,0 +1,98 @@
+package icons
+
+import (
+	"bytes"
+	"image"
+	"image/png"
+
+	"github.com/mbolis/mogo/cache"
+	"github.com/mbolis/mogo/phase"
+	"github.com/mbolis/mogo/sign"
+	"github.com/mbolis/mogo/status"
+	"github.com/mbolis/mogo/template"
+	"github.com/srwiley/oksvg"
+	"github.com/srwiley/rasterx"
+)
+
+// ImageSize is the side in pixels of the PNG renditions of icons.
+const ImageSize = 64
+
+// Image is a bundled icon, available both as SVG and as PNG for
+// applications which cannot render vector images.
+type Image struct {
+	Name string
+	SVG  []byte
+	PNG  []byte
+}
+
+var imageCache = cache.New[string, Image](64)
+
+func loadImage(name string) Image {
+	return imageCache.GetOrCompute(name, renderImage)
+}
+
+func renderImage(name string) Image {
+	svg, err := template.Icon(name)
+	if err != nil {
+		panic(err)
+	}
+
+	icon, err := oksvg.ReadIconStream(bytes.NewReader(svg))
+	if err != nil {
+		panic(err)
+	}
+	icon.SetTarget(0, 0, ImageSize, ImageSize)
+
+	rgba := image.NewRGBA(image.Rect(0, 0, ImageSize, ImageSize))
+	scanner := rasterx.NewScannerGV(ImageSize, ImageSize, rgba, rgba.Bounds())
+	icon.Draw(rasterx.NewDasher(ImageSize, ImageSize, scanner), 1)
+
+	var buf bytes.Buffer
+	err = png.Encode(&buf, rgba)
+	if err != nil {
+		panic(err)
+	}
+
+	return Image{name, svg, buf.Bytes()}
+}
+
+func PhaseImage(ph phase.Phase) Image {
+	for key, p := range phasesByKey {
+		if p == ph {
+			return loadImage("phase-" + key)
+		}
+	}
+	panic("impossible moon phase")
+}
+
+func SignImage(s sign.Sign) Image {
+	for key, v := range signsByKey {
+		if v == s {
+			return loadImage("sign-" + key)
+		}
+	}
+	panic("unknown sign")
+}
+
+// StatusImages returns the images standing for s, repeated as its
+// textual icons would be; neutral statuses have none.
+func StatusImages(s status.Status) []Image {
+	switch {
+	case s == status.Warning:
+		return []Image{loadImage("status-warning")}
+	case s < 0:
+		return repeat(loadImage("status-negative"), -int(s))
+	case s > 0:
+		return repeat(loadImage("status-positive"), int(s))
+	default:
+		return nil
+	}
+}
+
+func repeat(img Image, n int) []Image {
+	images := make([]Image, n)
+	for i := range images {
+		images[i] = img
+	}
+	return images
+}
@@ -0,0 +1,218 @@
+package icons
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/BurntSushi/toml"
+	"github.com/mbolis/mogo/element"
+	"github.com/mbolis/mogo/phase"
+	"github.com/mbolis/mogo/sign"
+)
+
+var phasesByKey = map[string]phase.Phase{
+	"new":             phase.New,
+	"waxing-crescent": phase.Waxing1,
+	"first-quarter":   phase.Waxing2,
+	"waxing-gibbous":  phase.Waxing3,
+	"full":            phase.Full,
+	"waning-gibbous":  phase.Waning1,
+	"last-quarter":    phase.Waning2,
+	"waning-crescent": phase.Waning3,
+}
+
+var signsByKey = map[string]sign.Sign{
+	"aries":       sign.Aries,
+	"taurus":      sign.Taurus,
+	"gemini":      sign.Gemini,
+	"cancer":      sign.Cancer,
+	"leo":         sign.Leo,
+	"virgo":       sign.Virgo,
+	"libra":       sign.Libra,
+	"scorpio":     sign.Scorpio,
+	"sagittarius": sign.Sagittarius,
+	"capricorn":   sign.Capricorn,
+	"aquarius":    sign.Aquarius,
+	"pisces":      sign.Pisces,
+	"ophiuchus":   sign.Ophiuchus,
+}
+
+// elementsByKey accepts both the elements and the organs of the plant.
+var elementsByKey = map[string]element.Element{
+	"earth":  element.Earth,
+	"root":   element.Earth,
+	"light":  element.Light,
+	"flower": element.Light,
+	"water":  element.Water,
+	"leaf":   element.Water,
+	"warmth": element.Warmth,
+	"fruit":  element.Warmth,
+}
+
+// PackDef is the definition of an icon pack in a configuration file:
+//
+//	[packs.spa]
+//	positive = "🌿"
+//	negative = "🥀"
+//	warning = "⚠"
+//	phases = { full = "🌝" }
+//	signs = { leo = "🦁" }
+//	elements = { root = "🌰" }
+//	ascii = { positive = "+", negative = "-", warning = "?" }
+type PackDef struct {
+	Positive string            `toml:"positive"`
+	Negative string            `toml:"negative"`
+	Warning  string            `toml:"warning"`
+	Phases   map[string]string `toml:"phases"`
+	Signs    map[string]string `toml:"signs"`
+	Elements map[string]string `toml:"elements"`
+	ASCII    *PackDef          `toml:"ascii"`
+}
+
+type packsFile struct {
+	Packs map[string]PackDef `toml:"packs"`
+}
+
+// Load reads icon pack definitions, validating every one of them.
+func Load(r io.Reader) (map[string]Style, error) {
+	var f packsFile
+	md, err := toml.NewDecoder(r).Decode(&f)
+	if err != nil {
+		return nil, err
+	}
+	if undecoded := md.Undecoded(); len(undecoded) > 0 {
+		return nil, fmt.Errorf("unknown icon pack settings: %v", undecoded)
+	}
+
+	return Build(f.Packs)
+}
+
+// Build validates pack definitions and turns them into styles.
+func Build(defs map[string]PackDef) (map[string]Style, error) {
+	packs := make(map[string]Style)
+	var errs []error
+	for name, def := range defs {
+		style, packErrs := def.build(strings.ToLower(name), false)
+		for _, err := range packErrs {
+			errs = append(errs, fmt.Errorf("icon pack '%s': %w", name, err))
+		}
+		if len(packErrs) == 0 {
+			packs[style.Name] = style
+		}
+	}
+	return packs, errors.Join(errs...)
+}
+
+func (def PackDef) build(name string, ascii bool) (style Style, errs []error) {
+	if _, ok := Builtin()[name]; ok && !ascii {
+		return style, []error{errors.New("cannot redefine builtin pack")}
+	}
+
+	style = Style{
+		Name:     name,
+		Positive: def.Positive,
+		Negative: def.Negative,
+		Warning:  def.Warning,
+		Phases:   make(map[phase.Phase]string),
+		Signs:    make(map[sign.Sign]string),
+		Elements: make(map[element.Element]string),
+	}
+
+	check := func(what, icon string) {
+		switch {
+		case icon == "":
+			errs = append(errs, fmt.Errorf("missing %s icon", what))
+		case ascii && !isASCII(icon):
+			errs = append(errs, fmt.Errorf("%s icon '%s' is not plain ASCII", what, icon))
+		}
+	}
+
+	check("positive", def.Positive)
+	check("negative", def.Negative)
+	check("warning", def.Warning)
+
+	for key, icon := range def.Phases {
+		ph, ok := phasesByKey[strings.ToLower(key)]
+		if !ok {
+			errs = append(errs, fmt.Errorf("unknown phase '%s', expected one of: %s", key, keys(phasesByKey)))
+			continue
+		}
+		check(key, icon)
+		style.Phases[ph] = icon
+	}
+
+	for key, icon := range def.Signs {
+		s, ok := signsByKey[strings.ToLower(key)]
+		if !ok {
+			errs = append(errs, fmt.Errorf("unknown sign '%s', expected one of: %s", key, keys(signsByKey)))
+			continue
+		}
+		check(key, icon)
+		style.Signs[s] = icon
+	}
+
+	for key, icon := range def.Elements {
+		e, ok := elementsByKey[strings.ToLower(key)]
+		if !ok {
+			errs = append(errs, fmt.Errorf("unknown element '%s', expected one of: %s", key, keys(elementsByKey)))
+			continue
+		}
+		check(key, icon)
+		style.Elements[e] = icon
+	}
+
+	switch {
+	case ascii && def.ASCII != nil:
+		errs = append(errs, errors.New("ascii fallback cannot be nested"))
+	case def.ASCII != nil:
+		fallback, fallbackErrs := def.ASCII.build(name, true)
+		for _, err := range fallbackErrs {
+			errs = append(errs, fmt.Errorf("ascii fallback: %w", err))
+		}
+		fallback.fillFrom(ASCII)
+		style.ASCII = &fallback
+	}
+
+	return style, errs
+}
+
+// fillFrom copies the phase, sign and element icons not overridden by style.
+func (style *Style) fillFrom(defaults Style) {
+	for ph, icon := range defaults.Phases {
+		if _, ok := style.Phases[ph]; !ok {
+			style.Phases[ph] = icon
+		}
+	}
+	for s, icon := range defaults.Signs {
+		if _, ok := style.Signs[s]; !ok {
+			style.Signs[s] = icon
+		}
+	}
+	for e, icon := range defaults.Elements {
+		if _, ok := style.Elements[e]; !ok {
+			style.Elements[e] = icon
+		}
+	}
+}
+
+func isASCII(s string) bool {
+	for _, r := range s {
+		if r > unicode.MaxASCII || !unicode.IsPrint(r) {
+			return false
+		}
+	}
+	return true
+}
+
+func keys[T any](m map[string]T) string {
+	var keys []string
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return strings.Join(keys, ", ")
+}
@@ -1,7 +1,10 @@
 package jd
 
 import (
+	"errors"
+	"fmt"
 	"math"
+	"strings"
 	"time"
 
 	"github.com/mbolis/mogo/util"
@@ -9,31 +12,123 @@ import (
 	"github.com/soniakeys/meeus/v3/julian"
 )
 
+// Scale is the time scale times are read and written in. Julian days are
+// always in Terrestrial Time, the uniform time of the ephemeris.
+type Scale int
+
+const (
+	// UT is civil time, following the rotation of the Earth
+	UT Scale = iota
+	// TT is Terrestrial Time, ahead of UT by Delta-T
+	TT
+)
+
+func ParseScale(s string) (Scale, error) {
+	switch strings.ToLower(s) {
+	case "ut", "utc":
+		return UT, nil
+	case "tt", "et":
+		return TT, nil
+	default:
+		return UT, fmt.Errorf("unrecognized time scale '%s', expected one of: ut, tt", s)
+	}
+}
+
+func (s Scale) String() string {
+	switch s {
+	case UT:
+		return "UT"
+	case TT:
+		return "TT"
+	default:
+		panic(fmt.Sprintf("unknown time scale: %d", s))
+	}
+}
+
+var scale = UT
+
+// SetScale sets the time scale of the conversions between times and Julian
+// days, for the whole program: it is not safe to call it while they run.
+// config.FlagSet.Parse sets the scale of the command line.
+func SetScale(s Scale) {
+	scale = s
+}
+
+// DeltaT returns TT - UT at d.
+func DeltaT(d time.Time) time.Duration {
+	dt := swephgo.Deltat(FromTimeUT(d))
+	return time.Duration(dt * 86400 * float64(time.Second))
+}
+
+// Times are in the proleptic Gregorian calendar, as in the time package, at
+// any date: the calendars of the dates shown are handled by model.Calendar.
+const gregflag = swephgo.SeGregCal
+
+// The Swiss Ephemeris falls back to the Moshier ephemeris without its data
+// files, which covers the years from 3001 BC to AD 3000.
+const (
+	MinJD = 625000.5
+	MaxJD = 2818000.5
+)
+
+// CheckRange returns an error unless the ephemeris covers the times from
+// start to end, with a margin for the events looked for around them.
+func CheckRange(start, end time.Time) error {
+	const margin = 60
+	errRange := errors.New("the ephemeris covers the years from -3000 to 3000 only")
+
+	_, first, err := fromTime(start.AddDate(0, 0, -margin))
+	if err != nil {
+		return errRange
+	}
+	_, last, err := fromTime(end.AddDate(0, 0, margin))
+	if err != nil {
+		return errRange
+	}
+	if first < MinJD || last > MaxJD {
+		return errRange
+	}
+	return nil
+}
+
+// FromTime converts a time in the range checked by CheckRange: it panics
+// on the others.
 func FromTime(d time.Time) float64 {
-	d = d.In(time.UTC)
-	et, _ := fromTime(d)
+	et, _, err := fromTime(d)
+	if err != nil {
+		panic(err)
+	}
 	return et
 }
 
 func FromTimeUT(d time.Time) float64 {
-	d = d.In(time.UTC)
-	_, ut := fromTime(d)
+	_, ut, err := fromTime(d)
+	if err != nil {
+		panic(err)
+	}
 	return ut
 }
 
-func fromTime(d time.Time) (et float64, ut float64) {
+func fromTime(d time.Time) (et float64, ut float64, err error) {
 	d = d.In(time.UTC)
+	if scale == TT {
+		hour := float64(d.Hour()) + float64(d.Minute())/60 + (float64(d.Second())+float64(d.Nanosecond())/1e9)/3600
+		et = swephgo.Julday(d.Year(), int(d.Month()), d.Day(), hour, gregflag)
+		// Delta-T takes UT, but changes by less than a second in a day
+		return et, et - swephgo.Deltat(et-swephgo.Deltat(et)), nil
+	}
+
 	var ret [2]float64
 	var errMsg [256]byte
 	if r := swephgo.UtcToJd(
 		d.Year(), int(d.Month()), d.Day(),
 		d.Hour(), d.Minute(), float64(d.Second())+float64(d.Nanosecond())/1000000000,
-		swephgo.SeGregCal,
+		gregflag,
 		ret[:], errMsg[:],
 	); r == swephgo.Err {
-		panic(util.NTString(errMsg[:]))
+		return 0, 0, errors.New(util.NTString(errMsg[:]))
 	}
-	return ret[0], ret[1]
+	return ret[0], ret[1], nil
 }
 
 func Time(jd float64) time.Time {
@@ -45,6 +140,17 @@ func TimeUT(jd float64) time.Time {
 }
 
 func toTime(jd float64, ut bool) time.Time {
+	if scale == TT {
+		if ut {
+			jd += swephgo.Deltat(jd)
+		}
+		var year, month, day [1]int
+		var hour [1]float64
+		swephgo.Revjul(jd, gregflag, year[:], month[:], day[:], hour[:])
+		return time.Date(year[0], time.Month(month[0]), day[0], 0, 0, 0, 0, time.UTC).
+			Add(time.Duration(hour[0] * float64(time.Hour)))
+	}
+
 	var jdToUTC func(jd float64, gregflag int, year []int, month []int, day []int, hour []int, min []int, sec []float64)
 	if ut {
 		jdToUTC = swephgo.Jdut1ToUtc
@@ -55,7 +161,7 @@ func toTime(jd float64, ut bool) time.Time {
 	var year, month, day [1]int
 	var hour, min [1]int
 	var sec [1]float64
-	jdToUTC(jd, swephgo.SeGregCal, year[:], month[:], day[:], hour[:], min[:], sec[:])
+	jdToUTC(jd, gregflag, year[:], month[:], day[:], hour[:], min[:], sec[:])
 
 	s, f := math.Modf(sec[0])
 	return time.Date(year[0], time.Month(month[0]), day[0], hour[0], min[0], int(s), int(f*1000000000), time.UTC)
@@ -0,0 +1,115 @@
+package jd
+
+import (
+	"math/rand"
+	"testing"
+	"time"
+)
+
+// precision is the round-trip error allowed by the floating point
+// representation of Julian days, about 20µs in this century.
+const precision = time.Millisecond
+
+func randomTime(rnd *rand.Rand) time.Time {
+	start := time.Date(1900, 1, 1, 0, 0, 0, 0, time.UTC)
+	return start.Add(time.Duration(rnd.Int63n(int64(200 * 365 * 24 * time.Hour))))
+}
+
+func TestRoundTrip(t *testing.T) {
+	rnd := rand.New(rand.NewSource(1))
+	for i := 0; i < 1000; i++ {
+		want := randomTime(rnd)
+		if got := Time(FromTime(want)); got.Sub(want).Abs() > precision {
+			t.Fatalf("Time(FromTime(%s)) = %s", want, got)
+		}
+		if got := TimeUT(FromTimeUT(want)); got.Sub(want).Abs() > precision {
+			t.Fatalf("TimeUT(FromTimeUT(%s)) = %s", want, got)
+		}
+	}
+}
+
+func TestFromTimeIgnoresLocation(t *testing.T) {
+	rome, err := time.LoadLocation("Europe/Rome")
+	if err != nil {
+		t.Skip(err)
+	}
+
+	rnd := rand.New(rand.NewSource(2))
+	for i := 0; i < 100; i++ {
+		d := randomTime(rnd)
+		if FromTime(d) != FromTime(d.In(rome)) {
+			t.Fatalf("FromTime(%s) depends on the location", d)
+		}
+	}
+}
+
+func TestMonotonic(t *testing.T) {
+	rnd := rand.New(rand.NewSource(3))
+	for i := 0; i < 1000; i++ {
+		d := randomTime(rnd)
+		if a, b := FromTime(d), FromTime(d.Add(time.Second)); b <= a {
+			t.Fatalf("FromTime(%s) = %f, not before %f a second later", d, a, b)
+		}
+	}
+}
+
+func TestHalfMinute(t *testing.T) {
+	if got := time.Duration(HalfMinute * 24 * float64(time.Hour)); (got - 30*time.Second).Abs() > time.Microsecond {
+		t.Errorf("HalfMinute = %s", got)
+	}
+}
+
+func TestScaleTT(t *testing.T) {
+	SetScale(TT)
+	defer SetScale(UT)
+
+	rnd := rand.New(rand.NewSource(4))
+	for i := 0; i < 1000; i++ {
+		want := randomTime(rnd)
+		if got := Time(FromTime(want)); got.Sub(want).Abs() > precision {
+			t.Fatalf("Time(FromTime(%s)) = %s in TT", want, got)
+		}
+		if got := TimeUT(FromTimeUT(want)); got.Sub(want).Abs() > precision {
+			t.Fatalf("TimeUT(FromTimeUT(%s)) = %s in TT", want, got)
+		}
+	}
+}
+
+// TestScalesDifferByDeltaT checks that the same Julian day reads Delta-T
+// later in TT than in UT.
+func TestScalesDifferByDeltaT(t *testing.T) {
+	d := time.Date(2024, time.June, 1, 12, 0, 0, 0, time.UTC)
+	jd := FromTime(d)
+	dt := DeltaT(d)
+	if dt < 60*time.Second || dt > 80*time.Second {
+		t.Errorf("DeltaT(%s) = %s, want about 69s", d, dt)
+	}
+
+	SetScale(TT)
+	defer SetScale(UT)
+	if got := Time(jd).Sub(d); (got - dt).Abs() > precision {
+		t.Errorf("TT - UT = %s, want %s", got, dt)
+	}
+}
+
+func TestCheckRange(t *testing.T) {
+	year := func(y int) time.Time { return time.Date(y, time.January, 1, 0, 0, 0, 0, time.UTC) }
+	if err := CheckRange(year(1450), year(1451)); err != nil {
+		t.Errorf("1450: %v", err)
+	}
+	if err := CheckRange(year(-3000), year(-2999)); err != nil {
+		t.Errorf("-3000: %v", err)
+	}
+	if err := CheckRange(year(3000), year(3001)); err != nil {
+		t.Errorf("3000: %v", err)
+	}
+	if err := CheckRange(year(3500), year(3501)); err == nil {
+		t.Error("3500 is not covered")
+	}
+	if err := CheckRange(year(-3100), year(-3099)); err == nil {
+		t.Error("-3100 is not covered")
+	}
+	if err := CheckRange(year(1000000), year(1000001)); err == nil {
+		t.Error("1000000 is not covered")
+	}
+}
@@ -0,0 +1,185 @@
+package lunarday
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/mbolis/mogo/jd"
+	"github.com/mbolis/mogo/model"
+	"github.com/mbolis/mogo/observer"
+	"github.com/mbolis/mogo/phase"
+)
+
+// Mode is the way lunar days are reckoned.
+type Mode int
+
+const (
+	// Tithi divides the lunation in 30 days, one every 12° of elongation
+	Tithi Mode = iota
+	// Moonrise starts lunar day 1 at new moon and every other at moonrise
+	Moonrise
+)
+
+func ParseMode(s string) (Mode, error) {
+	switch strings.ToLower(s) {
+	case "tithi":
+		return Tithi, nil
+	case "moonrise":
+		return Moonrise, nil
+	default:
+		return Tithi, fmt.Errorf("unrecognized lunar day mode '%s', expected one of: tithi, moonrise", s)
+	}
+}
+
+func (m Mode) String() string {
+	switch m {
+	case Tithi:
+		return "tithi"
+	case Moonrise:
+		return "moonrise"
+	default:
+		panic(fmt.Sprintf("unknown lunar day mode: %d", m))
+	}
+}
+
+// Day holds the lunar day at the start of a civil day and its changes
+// during the day.
+type Day struct {
+	Start   int
+	Changes []model.Event[int]
+}
+
+// At returns the lunar day at t, which must fall within the day.
+func (d Day) At(t time.Time) int {
+	n := d.Start
+	for _, c := range d.Changes {
+		if !c.Time.After(t) {
+			n = c.Value
+		}
+	}
+	return n
+}
+
+// TithiOf returns the lunar day [1-30] of the elongation of v.
+func TithiOf(v phase.Value) int {
+	return int(elongation(v)/12) + 1
+}
+
+func elongation(v phase.Value) float64 {
+	if v.Ph < 0 {
+		return v.Ph + 360
+	}
+	return v.Ph
+}
+
+// ForDay computes the lunar days of the day of d. Moonrise mode needs
+// the place of the observer, and falls back to tithi without one.
+func ForDay(d time.Time, mode Mode, loc *observer.Location) Day {
+	return ForDays(model.DayStart(d), model.NextDay(d), mode, loc)[0]
+}
+
+// ForDays computes the lunar days of the days from start to end, which
+// must be day starts. Moonrise mode needs the place of the observer, and
+// falls back to tithi without one; it walks the moonrises from the new
+// moon before start only once.
+func ForDays(start, end time.Time, mode Mode, loc *observer.Location) []Day {
+	switch {
+	case mode == Moonrise && loc != nil:
+		return moonriseDays(start, end, *loc)
+	default:
+		var days []Day
+		for d := start; d.Before(end); d = model.NextDay(d) {
+			days = append(days, tithiDay(d, model.NextDay(d)))
+		}
+		return days
+	}
+}
+
+// tithiStarts holds the elongations where the lunar days begin.
+var tithiStarts = func() []float64 {
+	starts := make([]float64, 30)
+	for i := range starts {
+		starts[i] = float64(i * 12)
+	}
+	return starts
+}()
+
+func tithiDay(d0, d1 time.Time) (day Day) {
+	day.Start = TithiOf(phase.CalcTime(d0))
+	for _, c := range phase.Elongation.Crossings(tithiStarts, jd.FromTime(d0), jd.FromTime(d1)) {
+		day.Changes = append(day.Changes, model.Event[int]{
+			Time:  jd.Time(c.JD).In(d0.Location()),
+			Value: c.Target + 1,
+		})
+	}
+	return
+}
+
+// moonrises returns the moonrises from start to end, in the time zone of
+// start.
+func moonrises(start, end time.Time, loc observer.Location) []time.Time {
+	var rises []time.Time
+	for t := start; t.Before(end); {
+		next, ok := observer.Next(observer.Moonrise, t, loc)
+		if !ok {
+			// near the poles the Moon can stay above or below the
+			// horizon for days
+			t = t.Add(24 * time.Hour)
+			continue
+		}
+		if !next.Before(end) {
+			break
+		}
+		rises = append(rises, next.In(start.Location()))
+		// search past the rise, which the round trip through Julian
+		// days could find again
+		t = next.Add(time.Minute)
+	}
+	return rises
+}
+
+func moonriseDays(start, end time.Time, loc observer.Location) []Day {
+	first := phase.CalcTime(start)
+	newMoon := jd.Time(first.JD - first.Age()).In(start.Location())
+	moonrises := moonrises(newMoon, end, loc)
+
+	// number the moonrises since the last new moon, restarting the count
+	// at the new moons of the range
+	var changes []model.Event[int]
+	n := 1
+	for _, e := range phase.Events(start, end) {
+		if e.Value != phase.New {
+			continue
+		}
+		for len(moonrises) > 0 && !e.Time.Before(moonrises[0]) {
+			n++
+			changes = append(changes, model.Event[int]{Time: moonrises[0], Value: n})
+			moonrises = moonrises[1:]
+		}
+		n = 1
+		changes = append(changes, model.Event[int]{Time: e.Time, Value: n})
+	}
+	for _, moonrise := range moonrises {
+		n++
+		changes = append(changes, model.Event[int]{Time: moonrise, Value: n})
+	}
+
+	var days []Day
+	n = 1
+	for d := start; d.Before(end); d = model.NextDay(d) {
+		next := model.NextDay(d)
+		for len(changes) > 0 && changes[0].Time.Before(d) {
+			n = changes[0].Value
+			changes = changes[1:]
+		}
+		day := Day{Start: n}
+		for len(changes) > 0 && changes[0].Time.Before(next) {
+			n = changes[0].Value
+			day.Changes = append(day.Changes, changes[0])
+			changes = changes[1:]
+		}
+		days = append(days, day)
+	}
+	return days
+}
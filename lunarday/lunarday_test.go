@@ -0,0 +1,113 @@
+package lunarday
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/mbolis/mogo/model"
+	"github.com/mbolis/mogo/observer"
+	"github.com/mbolis/mogo/phase"
+)
+
+var rome = observer.Location{Name: "Rome", Latitude: 41.9, Longitude: 12.5}
+
+// newMoon is the new moon of 2024-01-11, published at 11:57 UTC, as found
+// by phase.Events.
+func newMoon(t *testing.T) time.Time {
+	published := time.Date(2024, time.January, 11, 11, 57, 0, 0, time.UTC)
+	for _, e := range phase.Events(published.Add(-time.Hour), published.Add(time.Hour)) {
+		if e.Value == phase.New {
+			return e.Time
+		}
+	}
+	t.Fatalf("no new moon around %s", published)
+	return time.Time{}
+}
+
+func TestTithi(t *testing.T) {
+	nm := newMoon(t)
+	tests := []struct {
+		day     time.Time
+		start   int
+		changes []int
+	}{
+		// the last tithi before the new moon, and the first after it
+		{time.Date(2024, time.January, 11, 0, 0, 0, 0, time.UTC), 30, []int{1}},
+		// the full moon, published at 17:54, starts tithi 16
+		{time.Date(2024, time.January, 25, 0, 0, 0, 0, time.UTC), 15, []int{16}},
+	}
+	for _, tt := range tests {
+		day := ForDay(tt.day, Tithi, nil)
+		var changes []int
+		for _, c := range day.Changes {
+			changes = append(changes, c.Value)
+		}
+		if day.Start != tt.start || !reflect.DeepEqual(changes, tt.changes) {
+			t.Errorf("%s: got %d, changes %v, want %d, changes %v",
+				tt.day.Format(time.DateOnly), day.Start, changes, tt.start, tt.changes)
+		}
+	}
+
+	day := ForDay(nm, Tithi, nil)
+	if len(day.Changes) != 1 || day.Changes[0].Time.Sub(nm).Abs() > time.Second {
+		t.Errorf("got changes %v, want tithi 1 at the new moon, %s", day.Changes, nm)
+	}
+}
+
+// TestMoonrises checks a month of moonrises, which come about 24h50m
+// apart, a little less or more as the Moon moves north or south.
+func TestMoonrises(t *testing.T) {
+	start := time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC)
+	end := start.AddDate(0, 1, 0)
+	rises := moonrises(start, end, rome)
+	if len(rises) < 28 || len(rises) > 31 {
+		t.Fatalf("got %d moonrises in a month, want one about every day", len(rises))
+	}
+	for i := 1; i < len(rises); i++ {
+		gap := rises[i].Sub(rises[i-1])
+		if gap < 24*time.Hour || gap > 26*time.Hour {
+			t.Errorf("got moonrises %s and %s, %s apart", rises[i-1], rises[i], gap)
+		}
+	}
+	mean := rises[len(rises)-1].Sub(rises[0]) / time.Duration(len(rises)-1)
+	if want := 24*time.Hour + 50*time.Minute; (mean - want).Abs() > 10*time.Minute {
+		t.Errorf("got moonrises %s apart on average, want about %s", mean, want)
+	}
+}
+
+// TestMoonriseNewMoon checks that the new moon restarts the count of the
+// moonrises, and the first moonrise after it starts lunar day 2.
+func TestMoonriseNewMoon(t *testing.T) {
+	nm := newMoon(t)
+	start := time.Date(2024, time.January, 10, 0, 0, 0, 0, time.UTC)
+	days := ForDays(start, start.AddDate(0, 0, 3), Moonrise, &rome)
+
+	var changes []model.Event[int]
+	for _, d := range days {
+		changes = append(changes, d.Changes...)
+	}
+	for i, c := range changes {
+		if c.Value != 1 {
+			continue
+		}
+		if c.Time.Sub(nm).Abs() > time.Second {
+			t.Errorf("got lunar day 1 at %s, want it at the new moon, %s", c.Time, nm)
+		}
+		if i == 0 || changes[i-1].Value < 29 {
+			t.Errorf("got changes %v, want lunar day 29 or 30 before the new moon", changes)
+		}
+		if i+1 == len(changes) || changes[i+1].Value != 2 {
+			t.Errorf("got changes %v, want lunar day 2 at the moonrise after the new moon", changes)
+		}
+		return
+	}
+	t.Errorf("got changes %v, want lunar day 1 at the new moon", changes)
+}
+
+func TestMoonriseWithoutPlace(t *testing.T) {
+	d := time.Date(2024, time.January, 11, 0, 0, 0, 0, time.UTC)
+	if got, want := ForDay(d, Moonrise, nil), ForDay(d, Tithi, nil); !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want the tithi %v", got, want)
+	}
+}
@@ -2,15 +2,16 @@ package main
 
 import (
 	"fmt"
-	"io"
 	"os"
-	"os/exec"
-	"path/filepath"
+	"strings"
 	"time"
 
 	"github.com/mbolis/mogo/config"
+	"github.com/mbolis/mogo/element"
 	"github.com/mbolis/mogo/i18n"
+	"github.com/mbolis/mogo/lunarday"
 	"github.com/mbolis/mogo/model"
+	"github.com/mbolis/mogo/observer"
 	"github.com/mbolis/mogo/phase"
 	"github.com/mbolis/mogo/sign"
 	"github.com/mbolis/mogo/status"
@@ -20,174 +21,189 @@ import (
 var T = i18n.T
 
 func main() {
-	cfg := config.Parse()
 	defer swephgo.Close()
 
-	i18n.SetLang(cfg.Lang)
-
-	start, end := cfg.Range()
+	args := os.Args[1:]
+	if len(args) > 0 && (args[0] == "-h" || args[0] == "--help" || args[0] == "help") {
+		printUsage(os.Stdout)
+		return
+	}
 
-	var days []Day
-	for d := start; d.Before(end); d = d.AddDate(0, 0, 1) {
-		ph := phase.ForDay(d)
-		sign := sign.ForDay(d)
-		days = append(days, Day{d, ph, sign})
-	}
-
-	var out io.WriteCloser
-	if cfg.Output == "-" {
-		out = os.Stdout
-	} else {
-		var err error
-		out, err = os.Create(cfg.Output)
-		if err != nil {
-			panic(err)
-		}
-		defer out.Close()
-	}
-
-	switch cfg.Format() {
-	case config.CSV:
-		GenerateCSV(cfg, days, out)
-	case config.XLSX:
-		GenerateXLSX(cfg, days, out)
-	case config.ODS:
-		GenerateODS(cfg, days, out)
-	case config.PDF:
-		tmp, err := os.MkdirTemp("", "mogo-")
-		if err != nil {
-			panic(err)
+	cmd := calendarCommand
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		var ok bool
+		cmd, ok = commandsByName()[args[0]]
+		if !ok {
+			fmt.Fprintf(os.Stderr, "unknown command '%s'\n\n", args[0])
+			printUsage(os.Stderr)
+			os.Exit(2)
 		}
-		defer os.RemoveAll(tmp)
+		args = args[1:]
+	}
 
-		odsOut, err := os.Create(filepath.Join(tmp, "out.ods"))
-		if err != nil {
-			panic(err)
-		}
-		defer odsOut.Close()
+	cmd.Run(args)
+}
 
-		GenerateODS(cfg, days, odsOut)
-		soffice, err := exec.LookPath("soffice")
-		if err == nil {
-			soffice, err = filepath.Abs(soffice)
-		}
-		if err != nil {
-			panic(err)
-		}
+// ComputeDays computes the days from start to end; the rising and
+// setting of the Sun and Moon are computed only if a place is given.
+func ComputeDays(cfg config.Config, start, end time.Time) []Day {
+	// phases and signs are searched over the whole range, then split by day
+	phases := phase.ForDays(start, end)
+	signs := sign.ForDays(start, end, cfg.Zodiac)
+	var elements []model.DailyValue[element.Element]
+	if cfg.ElementZodiac() != cfg.Zodiac {
+		elements = element.ForDays(start, end, cfg.ElementZodiac())
+	}
+	lunarDays := lunarday.ForDays(start, end, cfg.LunarDay, cfg.Location)
 
-		cmd := exec.Command(soffice, "--headless",
-			"-env:UserInstallation=file:///tmp/LibreOffice_Conversion_mogo",
-			"--convert-to", "pdf:calc_pdf_Export", "--outdir", tmp, odsOut.Name())
-		cmd.Stdout = os.Stdout
-		cmd.Stderr = os.Stderr
-		if err = cmd.Run(); err != nil {
-			panic(err)
+	var days []Day
+	for i, d := 0, start; d.Before(end); i, d = i+1, model.NextDay(d) {
+		day := Day{
+			Time:     d,
+			Phase:    phases[i],
+			Sign:     signs[i],
+			LunarDay: lunarDays[i],
 		}
-
-		pdf, err := os.Open(filepath.Join(tmp, "out.pdf"))
-		if err != nil {
-			panic(err)
+		if elements != nil {
+			day.Element = elements[i]
+		} else {
+			day.Element = element.FromSigns(day.Sign)
 		}
-		defer pdf.Close()
-
-		_, err = io.Copy(out, pdf)
-		if err != nil {
-			panic(err)
+		if cfg.Location != nil {
+			day.Sky = observer.ForDay(d, *cfg.Location)
 		}
+		days = append(days, day.round(cfg.Precision, cfg.Rounding))
 	}
+	return days
 }
 
 type Day struct {
 	Time  time.Time
 	Phase model.DailyValue[phase.Phase]
 	Sign  model.DailyValue[sign.Sign]
+	Sky   []model.Event[observer.Crossing]
+
+	LunarDay lunarday.Day
+	Element  model.DailyValue[element.Element]
+}
+
+// round brings the times of the events of the day to the precision p.
+func (d Day) round(p model.Precision, r model.Rounding) Day {
+	end := model.NextDay(d.Time)
+	d.Phase = d.Phase.Round(p, r, end)
+	d.Sign = d.Sign.Round(p, r, end)
+	d.Element = d.Element.Round(p, r, end)
+	d.Sky = model.RoundEvents(d.Sky, p, r, end)
+	d.LunarDay.Changes = model.RoundEvents(d.LunarDay.Changes, p, r, end)
+	return d
 }
 
 type Row struct {
 	status.Entry
-	cfg config.Config
+	cfg      config.Config
+	sky      []model.Event[observer.Crossing]
+	lunarDay lunarday.Day
+	element  model.DailyValue[element.Element]
 }
 
 func (r Row) PhaseText() (icon, name string) {
 	if r.Phase < 0 {
 		return "", ""
 	}
-	return string(r.cfg.Icons.Phase(r.Phase)), T("phase." + r.Phase.String())
+	return r.cfg.Icons.Phase(r.Phase), T("phase." + r.Phase.String())
 }
 
 func (r Row) SignText() (icon, name string) {
 	if r.Sign < 0 {
 		return "", ""
 	}
-	return string(r.cfg.Icons.Sign(r.Sign)), T("zodiac." + r.Sign.String())
+	return r.cfg.Icons.Sign(r.Sign), T("zodiac." + r.Sign.String())
+}
+
+// ElementText returns the element at the time of the row, along with
+// the organ of the plant it favours, as in "Root (Earth)".
+func (r Row) ElementText() (icon, name string) {
+	e := r.element.At(r.instant())
+	return r.cfg.Icons.Element(e), fmt.Sprintf("%s (%s)", T("organ."+e.Organ()), T("element."+e.String()))
+}
+
+// Verdict returns the verdict of the rules file for t, if any applies,
+// else the built-in one.
+func (r Row) Verdict(t status.Treatment) status.Status {
+	if s, ok := r.cfg.Rules.Verdict(t, r.Entry); ok {
+		return s
+	}
+	return r.Entry.Verdict(t)
+}
+
+func (r Row) Statuses() []status.Status {
+	var statuses []status.Status
+	for _, t := range status.Treatments {
+		statuses = append(statuses, r.Verdict(t))
+	}
+	return statuses
 }
 
 func (r Row) HaircutIcon() string {
-	return r.cfg.Icons.Status(r.Haircut())
+	return r.cfg.Icons.Status(r.Verdict(status.Haircut))
 }
 func (r Row) NailsCutIcon() string {
-	return r.cfg.Icons.Status(r.NailsCut())
+	return r.cfg.Icons.Status(r.Verdict(status.NailsCut))
 }
 func (r Row) EpilationIcon() string {
-	return r.cfg.Icons.Status(r.Epilation())
+	return r.cfg.Icons.Status(r.Verdict(status.Epilation))
 }
 func (r Row) FacialCleansingIcon() string {
-	return r.cfg.Icons.Status(r.FacialCleansing())
+	return r.cfg.Icons.Status(r.Verdict(status.FacialCleansing))
 }
 func (r Row) FaceMaskIcon() string {
-	return r.cfg.Icons.Status(r.FaceMask())
+	return r.cfg.Icons.Status(r.Verdict(status.FaceMask))
+}
+
+func Header(cfg config.Config) []string {
+	header := []string{
+		DateHeader(cfg, "Month"), T("Day"), T("Hour"), T("Phase"), "", T("Sign"), "",
+		T("Haircut"), T("Nails cut"), T("Epilation"), T("Facial cleansing"), T("Face mask"),
+	}
+	for _, c := range ExtraColumns(cfg) {
+		header = append(header, T(c.Header))
+	}
+	return header
 }
 
 func (r Row) Strings() []string {
-	month := T("month." + r.Date.Format("Jan"))
-	day := fmt.Sprintf("%s %d", T("weekday."+r.Date.Format("Mon")), r.Date.Day())
+	month, day := r.DateStrings()
 
 	time := ""
 	if !r.Time.IsZero() {
-		time = r.Time.Format("15:04")
+		time = clock(r.Time, r.cfg.Precision.Layout())
 	}
 
 	phaseIcon, phaseName := r.PhaseText()
 	signIcon, signName := r.SignText()
 
-	return []string{
+	strings := []string{
 		month, day, time, phaseIcon, phaseName, signIcon, signName,
 		r.HaircutIcon(), r.NailsCutIcon(), r.EpilationIcon(), r.FacialCleansingIcon(), r.FaceMaskIcon(),
 	}
+	for _, c := range ExtraColumns(r.cfg) {
+		strings = append(strings, c.String(r))
+	}
+	return strings
 }
 
-func (d Day) Rows(cfg config.Config) []Row {
-	var entries []status.Entry
-	switch {
-	case d.Phase.Event != nil && d.Sign.Event != nil:
-		switch {
-		case d.Phase.Event.Time.Before(d.Sign.Event.Time):
-			entries = []status.Entry{
-				{Date: d.Time, Time: d.Phase.Event.Time, Phase: d.Phase.Event.Value, Sign: d.Sign.Curr},
-				{Date: d.Time, Time: d.Sign.Event.Time, Phase: -1, Sign: d.Sign.Event.Value},
-			}
-		case d.Sign.Event.Time.Before(d.Phase.Event.Time):
-			entries = []status.Entry{
-				{Date: d.Time, Time: d.Sign.Event.Time, Phase: -1, Sign: d.Sign.Event.Value},
-				{Date: d.Time, Time: d.Phase.Event.Time, Phase: d.Phase.Event.Value, Sign: d.Sign.Event.Value},
-			}
-		case d.Phase.Event.Time == d.Sign.Event.Time:
-			entries = []status.Entry{
-				{Date: d.Time, Time: d.Phase.Event.Time, Phase: d.Phase.Event.Value, Sign: d.Sign.Event.Value},
-			}
-		}
-
-	case d.Phase.Event != nil:
-		entries = []status.Entry{
-			{Date: d.Time, Time: d.Phase.Event.Time, Phase: d.Phase.Event.Value, Sign: d.Sign.Curr},
-		}
-
-	case d.Sign.Event != nil:
-		entries = []status.Entry{
-			{Date: d.Time, Time: d.Sign.Event.Time, Phase: d.Phase.Curr, Sign: d.Sign.Event.Value},
-		}
+// DateStrings returns the month and the day of the row, in the calendar
+// of the configuration, as in "Mar" and "Mon 4".
+func (r Row) DateStrings() (month, day string) {
+	_, m, d := r.cfg.Calendar.Date(r.Date)
+	month = T("month." + m.String()[:3])
+	day = fmt.Sprintf("%s %d", T("weekday."+r.Date.Format("Mon")), d)
+	return
+}
 
-	default:
+func (d Day) Rows(cfg config.Config) []Row {
+	entries := d.entries()
+	if len(entries) == 0 {
 		entries = []status.Entry{
 			{Date: d.Time, Phase: d.Phase.Curr, Sign: d.Sign.Curr},
 		}
@@ -195,7 +211,52 @@ func (d Day) Rows(cfg config.Config) []Row {
 
 	var rows []Row
 	for _, e := range entries {
-		rows = append(rows, Row{e, cfg})
+		r := Row{Entry: e, cfg: cfg, sky: d.Sky, lunarDay: d.LunarDay, element: d.Element}
+		t := r.instant()
+		if cfg.IlluminationColumn || cfg.AgeColumn {
+			// the age searches the last new moon: only when shown
+			ph := phase.CalcTime(t)
+			r.Illumination = ph.Illumination
+			if cfg.AgeColumn {
+				r.Age = ph.Age()
+			}
+		}
+		r.LunarDay = d.LunarDay.At(t)
+
+		rows = append(rows, r)
 	}
 	return rows
 }
+
+// entries merges the phase and sign events of the day in chronological
+// order, one entry per instant. On days with a phase event, the entries
+// of the sign events alone have no phase.
+func (d Day) entries() []status.Entry {
+	otherPhase := d.Phase.Curr
+	if len(d.Phase.Events) > 0 {
+		otherPhase = -1
+	}
+
+	var entries []status.Entry
+	phases, signs := d.Phase.Events, d.Sign.Events
+	for len(phases) > 0 || len(signs) > 0 {
+		var t time.Time
+		switch {
+		case len(signs) == 0 || len(phases) > 0 && !signs[0].Time.Before(phases[0].Time):
+			t = phases[0].Time
+		default:
+			t = signs[0].Time
+		}
+
+		e := status.Entry{Date: d.Time, Time: t, Phase: otherPhase, Sign: d.Sign.At(t)}
+		if len(phases) > 0 && phases[0].Time.Equal(t) {
+			e.Phase = phases[0].Value
+			phases = phases[1:]
+		}
+		for len(signs) > 0 && signs[0].Time.Equal(t) {
+			signs = signs[1:]
+		}
+		entries = append(entries, e)
+	}
+	return entries
+}
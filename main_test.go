@@ -0,0 +1,181 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/mbolis/mogo/config"
+	"github.com/mbolis/mogo/element"
+	"github.com/mbolis/mogo/model"
+	"github.com/mbolis/mogo/phase"
+	"github.com/mbolis/mogo/sign"
+	"github.com/mbolis/mogo/status"
+)
+
+func TestDayEntries(t *testing.T) {
+	at := func(hour, min int) time.Time {
+		return time.Date(2024, time.January, 8, hour, min, 0, 0, time.UTC)
+	}
+	d0 := at(0, 0)
+
+	tests := []struct {
+		name   string
+		phases []model.Event[phase.Phase]
+		signs  []model.Event[sign.Sign]
+		want   []status.Entry
+	}{
+		{
+			name: "no events",
+		},
+		{
+			name:  "two ingresses",
+			signs: []model.Event[sign.Sign]{{Time: at(3, 0), Value: sign.Ophiuchus}, {Time: at(21, 0), Value: sign.Sagittarius}},
+			want: []status.Entry{
+				{Date: d0, Time: at(3, 0), Phase: phase.Waning3, Sign: sign.Ophiuchus},
+				{Date: d0, Time: at(21, 0), Phase: phase.Waning3, Sign: sign.Sagittarius},
+			},
+		},
+		{
+			name:   "phase between ingresses",
+			phases: []model.Event[phase.Phase]{{Time: at(12, 0), Value: phase.New}},
+			signs:  []model.Event[sign.Sign]{{Time: at(3, 0), Value: sign.Ophiuchus}, {Time: at(21, 0), Value: sign.Sagittarius}},
+			want: []status.Entry{
+				{Date: d0, Time: at(3, 0), Phase: -1, Sign: sign.Ophiuchus},
+				{Date: d0, Time: at(12, 0), Phase: phase.New, Sign: sign.Ophiuchus},
+				{Date: d0, Time: at(21, 0), Phase: -1, Sign: sign.Sagittarius},
+			},
+		},
+		{
+			name:   "phase with ingress",
+			phases: []model.Event[phase.Phase]{{Time: at(12, 0), Value: phase.New}},
+			signs:  []model.Event[sign.Sign]{{Time: at(12, 0), Value: sign.Ophiuchus}},
+			want: []status.Entry{
+				{Date: d0, Time: at(12, 0), Phase: phase.New, Sign: sign.Ophiuchus},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := Day{
+				Time:  d0,
+				Phase: model.DailyValue[phase.Phase]{Curr: phase.Waning3, Events: tt.phases},
+				Sign:  model.DailyValue[sign.Sign]{Curr: sign.Scorpio, Events: tt.signs},
+			}
+			got := d.entries()
+			if len(got) != len(tt.want) {
+				t.Fatalf("got %d entries %v, want %d", len(got), got, len(tt.want))
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Errorf("entry %d: got %+v, want %+v", i, got[i], tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+// TestComputeDaysDST checks that every day of the months when the clocks
+// change starts on its own date, and that its events fall within it.
+func TestComputeDaysDST(t *testing.T) {
+	months := []struct {
+		zone  string
+		month time.Month
+	}{
+		{"Europe/Rome", time.March},
+		{"Europe/Rome", time.October},
+		{"America/New_York", time.November},
+		{"Australia/Sydney", time.October},
+		{"America/Santiago", time.September},
+	}
+	for _, m := range months {
+		t.Run(m.zone+" "+m.month.String(), func(t *testing.T) {
+			loc, err := time.LoadLocation(m.zone)
+			if err != nil {
+				t.Skip(err)
+			}
+			cfg := config.Config{Year: 2024, Month: m.month, TZ: loc}
+			start, end := cfg.Range()
+
+			days := ComputeDays(cfg, start, end)
+			if want := time.Date(2024, m.month+1, 0, 0, 0, 0, 0, time.UTC).Day(); len(days) != want {
+				t.Fatalf("got %d days, want %d", len(days), want)
+			}
+			for i, d := range days {
+				if d.Time.Day() != i+1 {
+					t.Errorf("day %d starts at %s", i+1, d.Time)
+				}
+				d0, d1 := d.Time, model.NextDay(d.Time)
+				for _, r := range d.Rows(cfg) {
+					if !r.Time.IsZero() && (r.Time.Before(d0) || !r.Time.Before(d1)) {
+						t.Errorf("day %d: event at %s, out of [%s, %s)", i+1, r.Time, d0, d1)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestClock(t *testing.T) {
+	load := func(name string) *time.Location {
+		loc, err := time.LoadLocation(name)
+		if err != nil {
+			t.Skip(err)
+		}
+		return loc
+	}
+	rome, sydney, dublin := load("Europe/Rome"), load("Australia/Sydney"), load("Europe/Dublin")
+	cet := time.FixedZone("CET", 3600)
+
+	tests := []struct {
+		t    time.Time
+		want string
+	}{
+		{time.Date(2024, time.January, 10, 14, 5, 0, 0, rome), "14:05"},
+		{time.Date(2024, time.July, 10, 14, 5, 0, 0, rome), "14:05 +02:00"},
+		// 02:30 happens twice when the clocks go back
+		{time.Date(2024, time.October, 27, 2, 30, 0, 0, rome).Add(-time.Hour), "02:30 +02:00"},
+		{time.Date(2024, time.October, 27, 2, 30, 0, 0, rome), "02:30"},
+		// local mean time, before the zone had a standard offset
+		{time.Date(1850, time.July, 10, 14, 5, 0, 0, rome), "14:05"},
+		{time.Date(2024, time.July, 10, 14, 5, 0, 0, cet), "14:05"},
+		// summer is in January on the southern hemisphere
+		{time.Date(2024, time.January, 10, 14, 5, 0, 0, sydney), "14:05 +11:00"},
+		{time.Date(2024, time.July, 10, 14, 5, 0, 0, sydney), "14:05"},
+		// Irish Standard Time is in summer, with "negative DST" in winter
+		{time.Date(2024, time.January, 10, 14, 5, 0, 0, dublin), "14:05"},
+		{time.Date(2024, time.July, 10, 14, 5, 0, 0, dublin), "14:05 +01:00"},
+	}
+	for _, tt := range tests {
+		if got := clock(tt.t, "15:04"); got != tt.want {
+			t.Errorf("clock(%s) = %q, want %q", tt.t, got, tt.want)
+		}
+	}
+}
+
+// TestOphiuchus checks the verdicts and the elements of the days the Moon
+// enters and leaves Ophiuchus, on 2024-01-08 at 11:26 and the day after.
+func TestOphiuchus(t *testing.T) {
+	cfg := config.Config{Year: 2024, Month: time.January, TZ: time.UTC, Zodiac: sign.IAU}
+	start := time.Date(2024, time.January, 8, 0, 0, 0, 0, time.UTC)
+	days := ComputeDays(cfg, start, start.AddDate(0, 0, 2))
+
+	rows := days[0].Rows(cfg)
+	if len(rows) != 1 || rows[0].Sign != sign.Ophiuchus {
+		t.Fatalf("got rows %+v, want the ingress into Ophiuchus", rows)
+	}
+	scorpio := rows[0].Entry
+	scorpio.Sign = sign.Scorpio
+	for _, tr := range status.Treatments {
+		if got, want := rows[0].Verdict(tr), scorpio.Verdict(tr); got != want {
+			t.Errorf("%s in Ophiuchus: got %d, want %d as in Scorpio", tr, got, want)
+		}
+	}
+
+	if e := days[0].Element; e.Curr != element.Water || e.Next != element.Water || len(e.Events) != 0 {
+		t.Errorf("entering Ophiuchus: got elements %s, want Water throughout", e)
+	}
+	if e := days[1].Element; e.Curr != element.Water || len(e.Events) != 1 || e.Events[0].Value != element.Warmth {
+		t.Errorf("leaving Ophiuchus: got elements %s, want Water then Warmth", e)
+	}
+}
@@ -0,0 +1,135 @@
+package model
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"time"
+)
+
+// Calendar is the calendar dates are read and written in: the Julian
+// calendar before the reform, the Gregorian one since. Times are always
+// proleptic Gregorian, as in the time package.
+type Calendar struct {
+	// reform is the Julian Day Number of the first Gregorian day, but for
+	// the zero value, which is Gregorian
+	reform int
+}
+
+var (
+	// Gregorian is the proleptic Gregorian calendar
+	Gregorian = Calendar{}
+	// Julian is the proleptic Julian calendar
+	Julian = Calendar{math.MaxInt}
+	// Reform1582 switches on 1582-10-15, after 1582-10-04, as in Italy
+	Reform1582 = Calendar{2299161}
+)
+
+func ParseCalendar(s string) (Calendar, error) {
+	switch strings.ToLower(s) {
+	case "gregorian":
+		return Gregorian, nil
+	case "julian":
+		return Julian, nil
+	case "reform":
+		return Reform1582, nil
+	}
+
+	reform, err := time.Parse(time.DateOnly, s)
+	if err != nil {
+		return Gregorian, fmt.Errorf("unrecognized calendar '%s', expected one of: gregorian, julian, reform, or the date of the reform (YYYY-MM-DD)", s)
+	}
+	return Calendar{gregorianDayNumber(reform.Date())}, nil
+}
+
+func (c Calendar) String() string {
+	switch c {
+	case Gregorian:
+		return "gregorian"
+	case Julian:
+		return "julian"
+	default:
+		return c.Reform().Format(time.DateOnly)
+	}
+}
+
+// Reform returns the first Gregorian day, in UTC, or the zero time for the
+// proleptic calendars.
+func (c Calendar) Reform() time.Time {
+	if c == Gregorian || c == Julian {
+		return time.Time{}
+	}
+	y, m, d := gregorianDate(c.reform)
+	return time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+}
+
+// Date returns the date of the day of t in the calendar.
+func (c Calendar) Date(t time.Time) (year int, month time.Month, day int) {
+	year, month, day = t.Date()
+	if n := gregorianDayNumber(year, month, day); c.julian(n) {
+		return julianDate(n)
+	}
+	return
+}
+
+// InJulian tells whether the day of t is in the Julian part of the calendar.
+func (c Calendar) InJulian(t time.Time) bool {
+	return c.julian(gregorianDayNumber(t.Date()))
+}
+
+// Midnight is Midnight for a date of the calendar, normalized as in
+// time.Date. The dates the reform skips continue the Julian calendar.
+func (c Calendar) Midnight(y int, m time.Month, d int, loc *time.Location) time.Time {
+	if c != Gregorian {
+		n := julianDayNumber(y, m, d)
+		if g := gregorianDayNumber(y, m, d); !c.julian(n) && !c.julian(g) {
+			n = g
+		}
+		y, m, d = gregorianDate(n)
+	}
+	return Midnight(y, m, d, loc)
+}
+
+// julian tells whether the day number n is in the Julian calendar.
+func (c Calendar) julian(n int) bool {
+	return c != Gregorian && n < c.reform
+}
+
+// The day numbers count the months from March, so that the leap day comes
+// last, in the years from March to February.
+
+func gregorianDayNumber(y int, m time.Month, d int) int {
+	y, mm := marchYear(y, m)
+	return d + (153*mm+2)/5 + 365*y + floorDiv(y, 4) - floorDiv(y, 100) + floorDiv(y, 400) + 1721119
+}
+
+func julianDayNumber(y int, m time.Month, d int) int {
+	y, mm := marchYear(y, m)
+	return d + (153*mm+2)/5 + 365*y + floorDiv(y, 4) + 1721117
+}
+
+func marchYear(y int, m time.Month) (int, int) {
+	mm := int(m) - 3
+	return y + floorDiv(mm, 12), mm - 12*floorDiv(mm, 12)
+}
+
+func gregorianDate(n int) (int, time.Month, int) {
+	return time.Date(2000, time.January, 1+n-2451545, 0, 0, 0, 0, time.UTC).Date()
+}
+
+func julianDate(n int) (int, time.Month, int) {
+	c := n - 1721118 // days since 0000-03-01 Julian
+	y := floorDiv(4*c+3, 1461)
+	e := c - 365*y - floorDiv(y, 4) // day of the year from March
+	mm := (5*e + 2) / 153
+	d := e - (153*mm+2)/5 + 1
+	return y + floorDiv(mm+2, 12), time.Month((mm+2)%12 + 1), d
+}
+
+func floorDiv(a, b int) int {
+	q := a / b
+	if a%b != 0 && a < 0 {
+		q--
+	}
+	return q
+}
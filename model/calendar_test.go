@@ -0,0 +1,82 @@
+package model
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestCalendarDate(t *testing.T) {
+	tests := []struct {
+		calendar Calendar
+		time     string
+		date     string
+	}{
+		{Gregorian, "1582-10-04", "1582-10-04"},
+		{Julian, "1582-10-15", "1582-10-05"},
+		{Julian, "2024-03-14", "2024-03-01"},
+		{Julian, "1500-03-10", "1500-02-29"},
+		{Julian, "0100-03-01", "0100-03-02"},
+		{Reform1582, "1582-10-14", "1582-10-04"},
+		{Reform1582, "1582-10-15", "1582-10-15"},
+	}
+	for _, test := range tests {
+		tm, err := time.Parse(time.DateOnly, test.time)
+		if err != nil {
+			t.Fatal(err)
+		}
+		y, m, d := test.calendar.Date(tm)
+		if got := fmt.Sprintf("%04d-%02d-%02d", y, m, d); got != test.date {
+			t.Errorf("%s in %v: got %s, want %s", test.time, test.calendar, got, test.date)
+		}
+	}
+}
+
+func TestCalendarMidnight(t *testing.T) {
+	rome, err := time.LoadLocation("Europe/Rome")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		calendar Calendar
+		y        int
+		m        time.Month
+		d        int
+		want     string
+	}{
+		{Julian, 1500, time.February, 29, "1500-03-10"},
+		{Julian, 1500, time.February, 30, "1500-03-11"},
+		{Reform1582, 1582, time.October, 4, "1582-10-14"},
+		// the days after 1582-10-04 follow on, whether in the gap or not
+		{Reform1582, 1582, time.October, 5, "1582-10-15"},
+		{Reform1582, 1582, time.October, 15, "1582-10-15"},
+		{Reform1582, 1582, time.November, 1, "1582-11-01"},
+		{Reform1582, 1582, time.January, 1, "1582-01-11"},
+	}
+	for _, test := range tests {
+		got := test.calendar.Midnight(test.y, test.m, test.d, rome)
+		if got.Format(time.DateOnly) != test.want || got.Hour() != 0 {
+			t.Errorf("%d-%d-%d in %v: got %v, want %s", test.y, test.m, test.d, test.calendar, got, test.want)
+		}
+		if y, m, d := test.calendar.Date(got); test.calendar.Midnight(y, m, d, rome) != got {
+			t.Errorf("%v: %v does not round trip", test.calendar, got)
+		}
+	}
+}
+
+func TestParseCalendar(t *testing.T) {
+	c, err := ParseCalendar("1752-09-14")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if y, m, d := c.Date(time.Date(1752, time.September, 13, 12, 0, 0, 0, time.UTC)); y != 1752 || m != time.September || d != 2 {
+		t.Errorf("the day before the reform is %d-%d-%d, want 1752-9-2", y, m, d)
+	}
+	if s := c.String(); s != "1752-09-14" {
+		t.Errorf("got %s, want 1752-09-14", s)
+	}
+	if _, err := ParseCalendar("coptic"); err == nil {
+		t.Error("parsed an unknown calendar")
+	}
+}
@@ -5,27 +5,45 @@ import (
 	"time"
 )
 
+// DailyValue holds the value at the start of a day and of the next, and
+// the events which change it during the day, in chronological order.
 type DailyValue[T ~int] struct {
-	Curr  T
-	Next  T
-	Event *Event[T]
+	Curr   T
+	Next   T
+	Events []Event[T]
 }
 
 func (dv DailyValue[T]) String() string {
 	out := fmt.Sprintf("%v", dv.Curr)
-	if dv.Curr != dv.Next {
-		out += fmt.Sprintf("->%s->%v", dv.Event, dv.Next)
+	for _, e := range dv.Events {
+		out += fmt.Sprintf("->%s", &e)
+	}
+	if len(dv.Events) > 0 || dv.Curr != dv.Next {
+		out += fmt.Sprintf("->%v", dv.Next)
 	}
 	return out
 }
 
+// Value returns the value of the first event of the day, if any.
 func (dv DailyValue[T]) Value() T {
-	if dv.Event != nil {
-		return dv.Event.Value
+	if len(dv.Events) > 0 {
+		return dv.Events[0].Value
 	}
 	return dv.Curr
 }
 
+// At returns the value at t, which must fall within the day.
+func (dv DailyValue[T]) At(t time.Time) T {
+	v := dv.Curr
+	for _, e := range dv.Events {
+		if t.Before(e.Time) {
+			break
+		}
+		v = e.Value
+	}
+	return v
+}
+
 type Event[T ~int] struct {
 	Time  time.Time
 	Value T
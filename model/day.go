@@ -0,0 +1,29 @@
+package model
+
+import "time"
+
+// Midnight returns the first instant of the civil day y-m-d in loc, which
+// is normalized as in time.Date. Where the clocks skip midnight, the day
+// starts when they resume.
+func Midnight(y int, m time.Month, d int, loc *time.Location) time.Time {
+	y, m, d = time.Date(y, m, d, 12, 0, 0, 0, loc).Date()
+	start := time.Date(y, m, d, 0, 0, 0, 0, loc)
+	if start.Day() != d {
+		// normalized back into the previous day, before the gap
+		_, start = start.ZoneBounds()
+	}
+	return start
+}
+
+// DayStart returns the first instant of the civil day of t, in the
+// location of t.
+func DayStart(t time.Time) time.Time {
+	y, m, d := t.Date()
+	return Midnight(y, m, d, t.Location())
+}
+
+// NextDay returns the first instant of the civil day after the day of t.
+func NextDay(t time.Time) time.Time {
+	y, m, d := t.Date()
+	return Midnight(y, m, d+1, t.Location())
+}
@@ -0,0 +1,73 @@
+package model
+
+import (
+	"testing"
+	"time"
+)
+
+// dstDays are days when the clocks change, in zones which change them at
+// 02:00, 03:00 or midnight, forward and back.
+var dstDays = []struct {
+	zone       string
+	date       string
+	start      string
+	hours      int
+	nextOffset string
+}{
+	{"Europe/Rome", "2024-03-31", "2024-03-31T00:00:00+01:00", 23, "+02:00"},
+	{"Europe/Rome", "2024-10-27", "2024-10-27T00:00:00+02:00", 25, "+01:00"},
+	{"America/New_York", "2024-03-10", "2024-03-10T00:00:00-05:00", 23, "-04:00"},
+	{"America/New_York", "2024-11-03", "2024-11-03T00:00:00-04:00", 25, "-05:00"},
+	{"Australia/Sydney", "2024-04-07", "2024-04-07T00:00:00+11:00", 25, "+10:00"},
+	{"Australia/Sydney", "2024-10-06", "2024-10-06T00:00:00+10:00", 23, "+11:00"},
+	// midnight is skipped: the day starts at 01:00
+	{"America/Santiago", "2024-09-08", "2024-09-08T01:00:00-03:00", 23, "-03:00"},
+	{"Asia/Beirut", "2024-03-31", "2024-03-31T01:00:00+03:00", 23, "+03:00"},
+	// the clocks go back at midnight: the last hour of the day is repeated
+	{"America/Santiago", "2024-04-06", "2024-04-06T00:00:00-03:00", 25, "-04:00"},
+}
+
+func TestDayStart(t *testing.T) {
+	for _, tt := range dstDays {
+		t.Run(tt.zone+" "+tt.date, func(t *testing.T) {
+			loc, err := time.LoadLocation(tt.zone)
+			if err != nil {
+				t.Skip(err)
+			}
+			noon, err := time.ParseInLocation(time.DateOnly+" 15", tt.date+" 12", loc)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			start := DayStart(noon)
+			if got := start.Format(time.RFC3339); got != tt.start {
+				t.Errorf("got start %s, want %s", got, tt.start)
+			}
+			if !DayStart(start).Equal(start) {
+				t.Errorf("DayStart(%s) = %s, not the same", start, DayStart(start))
+			}
+
+			next := NextDay(noon)
+			if got := next.Sub(start); got != time.Duration(tt.hours)*time.Hour {
+				t.Errorf("got a day of %s, want %dh", got, tt.hours)
+			}
+			if got := next.Format("-07:00"); got != tt.nextOffset {
+				t.Errorf("got next day at %s, want offset %s", next, tt.nextOffset)
+			}
+			if _, _, d := next.Date(); d != noon.AddDate(0, 0, 1).Day() {
+				t.Errorf("next day starts at %s, not the day after %s", next, tt.date)
+			}
+		})
+	}
+}
+
+func TestMidnightNormalizes(t *testing.T) {
+	loc, err := time.LoadLocation("America/Santiago")
+	if err != nil {
+		t.Skip(err)
+	}
+	got := Midnight(2024, time.August, 39, loc)
+	if want := "2024-09-08T01:00:00-03:00"; got.Format(time.RFC3339) != want {
+		t.Errorf("got %s, want %s", got.Format(time.RFC3339), want)
+	}
+}
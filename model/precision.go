@@ -0,0 +1,140 @@
+package model
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Precision is the unit event times are given in.
+type Precision int
+
+const (
+	Minute Precision = iota
+	Second
+)
+
+func ParsePrecision(s string) (Precision, error) {
+	switch strings.ToLower(s) {
+	case "minute", "minutes", "m":
+		return Minute, nil
+	case "second", "seconds", "s":
+		return Second, nil
+	default:
+		return Minute, fmt.Errorf("unrecognized precision '%s', expected one of: minute, second", s)
+	}
+}
+
+func (p Precision) String() string {
+	switch p {
+	case Minute:
+		return "minute"
+	case Second:
+		return "second"
+	default:
+		panic(fmt.Sprintf("unknown precision: %d", p))
+	}
+}
+
+// Duration is the unit of p.
+func (p Precision) Duration() time.Duration {
+	if p == Second {
+		return time.Second
+	}
+	return time.Minute
+}
+
+// Layout is the time of day layout showing times to p.
+func (p Precision) Layout() string {
+	if p == Second {
+		return "15:04:05"
+	}
+	return "15:04"
+}
+
+// Rounding is how event times are brought to the precision.
+type Rounding int
+
+const (
+	Nearest Rounding = iota
+	Floor
+	Ceil
+)
+
+func ParseRounding(s string) (Rounding, error) {
+	switch strings.ToLower(s) {
+	case "nearest":
+		return Nearest, nil
+	case "floor", "down":
+		return Floor, nil
+	case "ceil", "up":
+		return Ceil, nil
+	default:
+		return Nearest, fmt.Errorf("unrecognized rounding '%s', expected one of: nearest, floor, ceil", s)
+	}
+}
+
+func (r Rounding) String() string {
+	switch r {
+	case Nearest:
+		return "nearest"
+	case Floor:
+		return "floor"
+	case Ceil:
+		return "ceil"
+	default:
+		panic(fmt.Sprintf("unknown rounding: %d", r))
+	}
+}
+
+// Round brings t to the precision p, on the wall clock of its location:
+// local mean times are offset by seconds from UTC.
+func Round(t time.Time, p Precision, r Rounding) time.Time {
+	_, offset := t.Zone()
+	shift := time.Duration(offset) * time.Second
+	wall := t.Add(shift)
+
+	var rounded time.Time
+	switch r {
+	case Floor:
+		rounded = wall.Truncate(p.Duration())
+	case Ceil:
+		rounded = wall.Truncate(p.Duration())
+		if rounded.Before(wall) {
+			rounded = rounded.Add(p.Duration())
+		}
+	default:
+		rounded = wall.Round(p.Duration())
+	}
+	return rounded.Add(-shift)
+}
+
+// Round brings the time of e to the precision p.
+func (e Event[T]) Round(p Precision, r Rounding) Event[T] {
+	e.Time = Round(e.Time, p, r)
+	return e
+}
+
+// RoundEvents brings the times of events to the precision p, in a new
+// slice. Those which would reach end are rounded down instead, so that
+// they stay within their day.
+func RoundEvents[T ~int](events []Event[T], p Precision, r Rounding, end time.Time) []Event[T] {
+	if events == nil {
+		return nil
+	}
+	rounded := make([]Event[T], len(events))
+	for i, e := range events {
+		rounded[i] = e.Round(p, r)
+		if !rounded[i].Time.Before(end) {
+			rounded[i] = e.Round(p, Floor)
+		}
+	}
+	return rounded
+}
+
+// Round brings the times of the events of dv, a day ending at end, to
+// the precision p.
+func (dv DailyValue[T]) Round(p Precision, r Rounding, end time.Time) DailyValue[T] {
+	dv.Events = RoundEvents(dv.Events, p, r, end)
+	return dv
+}
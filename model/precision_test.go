@@ -0,0 +1,66 @@
+package model
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRound(t *testing.T) {
+	rome, err := time.LoadLocation("Europe/Rome")
+	if err != nil {
+		t.Skip(err)
+	}
+
+	at := func(h, m, s, ns int) time.Time {
+		return time.Date(2024, time.March, 10, h, m, s, ns, rome)
+	}
+	tests := []struct {
+		t    time.Time
+		p    Precision
+		r    Rounding
+		want time.Time
+	}{
+		{at(14, 5, 29, 0), Minute, Nearest, at(14, 5, 0, 0)},
+		{at(14, 5, 30, 0), Minute, Nearest, at(14, 6, 0, 0)},
+		{at(14, 5, 59, 0), Minute, Floor, at(14, 5, 0, 0)},
+		{at(14, 5, 1, 0), Minute, Ceil, at(14, 6, 0, 0)},
+		{at(14, 5, 0, 0), Minute, Ceil, at(14, 5, 0, 0)},
+		{at(14, 5, 7, 600e6), Second, Nearest, at(14, 5, 8, 0)},
+		{at(14, 5, 7, 600e6), Second, Floor, at(14, 5, 7, 0)},
+		{at(14, 5, 7, 100e6), Second, Ceil, at(14, 5, 8, 0)},
+	}
+	for _, tt := range tests {
+		if got := Round(tt.t, tt.p, tt.r); !got.Equal(tt.want) {
+			t.Errorf("Round(%s, %s, %s) = %s, want %s", tt.t.Format(time.RFC3339Nano), tt.p, tt.r, got, tt.want)
+		}
+	}
+}
+
+// TestRoundLocalMeanTime checks that times are rounded on the wall clock
+// even where the offset is not a whole number of minutes.
+func TestRoundLocalMeanTime(t *testing.T) {
+	lmt := time.FixedZone("LMT", 49*60+56)
+	got := Round(time.Date(1850, time.May, 1, 10, 20, 40, 0, lmt), Minute, Floor)
+	if want := time.Date(1850, time.May, 1, 10, 20, 0, 0, lmt); !got.Equal(want) {
+		t.Errorf("got %s, want %s", got, want)
+	}
+}
+
+func TestRoundEventsWithinDay(t *testing.T) {
+	end := time.Date(2024, time.March, 11, 0, 0, 0, 0, time.UTC)
+	events := []Event[int]{
+		{Time: end.Add(-90 * time.Second), Value: 1},
+		{Time: end.Add(-20 * time.Second), Value: 2},
+	}
+
+	got := RoundEvents(events, Minute, Nearest, end)
+	if want := end.Add(-time.Minute); !got[0].Time.Equal(want) {
+		t.Errorf("got %s, want %s", got[0].Time, want)
+	}
+	if want := end.Add(-time.Minute); !got[1].Time.Equal(want) {
+		t.Errorf("got %s, want %s, within the day", got[1].Time, want)
+	}
+	if !events[1].Time.Equal(end.Add(-20 * time.Second)) {
+		t.Error("the events were modified")
+	}
+}
@@ -0,0 +1,157 @@
+package observer
+
+import (
+	"bytes"
+	_ "embed"
+	"encoding/csv"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mbolis/mogo/jd"
+	"github.com/mbolis/mogo/model"
+	"github.com/mbolis/mogo/util"
+	"github.com/mshafiee/swephgo"
+)
+
+// Location is the place of the observer; elevation is in meters.
+type Location struct {
+	Name      string
+	Latitude  float64
+	Longitude float64
+	Elevation float64
+}
+
+func (l Location) Validate() error {
+	if l.Latitude < -90 || l.Latitude > 90 {
+		return fmt.Errorf("latitude %g out of range [-90, 90]", l.Latitude)
+	}
+	if l.Longitude < -180 || l.Longitude > 180 {
+		return fmt.Errorf("longitude %g out of range [-180, 180]", l.Longitude)
+	}
+	return nil
+}
+
+//go:embed cities.csv
+var citiesCSV []byte
+
+// Lookup finds a city of the bundled gazetteer by its English or Italian
+// name, optionally followed by the country code, as in "Rome" or "roma, it".
+func Lookup(name string) (Location, error) {
+	name, country, _ := strings.Cut(strings.ToLower(name), ",")
+	name = strings.TrimSpace(name)
+	country = strings.TrimSpace(country)
+
+	records, err := csv.NewReader(bytes.NewReader(citiesCSV)).ReadAll()
+	if err != nil {
+		panic(err)
+	}
+
+	for _, record := range records[1:] {
+		if country != "" && country != strings.ToLower(record[1]) {
+			continue
+		}
+		for _, alias := range strings.Split(record[0], "|") {
+			if strings.ToLower(alias) != name {
+				continue
+			}
+
+			loc := Location{Name: strings.Split(record[0], "|")[0]}
+			loc.Latitude, _ = strconv.ParseFloat(record[2], 64)
+			loc.Longitude, _ = strconv.ParseFloat(record[3], 64)
+			loc.Elevation, _ = strconv.ParseFloat(record[4], 64)
+			return loc, nil
+		}
+	}
+	return Location{}, fmt.Errorf("unknown city '%s', expected one of: %s", name, Cities())
+}
+
+// Cities lists the names of the cities in the gazetteer.
+func Cities() string {
+	records, err := csv.NewReader(bytes.NewReader(citiesCSV)).ReadAll()
+	if err != nil {
+		panic(err)
+	}
+
+	var names []string
+	for _, record := range records[1:] {
+		names = append(names, strings.Split(record[0], "|")[0])
+	}
+	sort.Strings(names)
+	return strings.Join(names, ", ")
+}
+
+// Crossing is the passage of the Sun or the Moon over the horizon.
+type Crossing int
+
+const (
+	Sunrise Crossing = iota
+	Sunset
+	Moonrise
+	Moonset
+)
+
+func (c Crossing) String() string {
+	switch c {
+	case Sunrise:
+		return "Sunrise"
+	case Sunset:
+		return "Sunset"
+	case Moonrise:
+		return "Moonrise"
+	case Moonset:
+		return "Moonset"
+	default:
+		panic(fmt.Sprintf("unknown crossing: %d", c))
+	}
+}
+
+func (c Crossing) planet() int {
+	if c == Sunrise || c == Sunset {
+		return swephgo.SeSun
+	}
+	return swephgo.SeMoon
+}
+
+func (c Crossing) flag() int {
+	if c == Sunrise || c == Moonrise {
+		return swephgo.SeCalcRise
+	}
+	return swephgo.SeCalcSet
+}
+
+// ForDay returns the crossings happening on the day of d, by time.
+// The Moon does not rise or set every day, and near the poles
+// neither does the Sun.
+func ForDay(d time.Time, loc Location) []model.Event[Crossing] {
+	d0, d1 := model.DayStart(d), model.NextDay(d)
+
+	var events []model.Event[Crossing]
+	for _, c := range []Crossing{Sunrise, Sunset, Moonrise, Moonset} {
+		t, ok := Next(c, d0, loc)
+		if ok && t.Before(d1) {
+			events = append(events, model.Event[Crossing]{Time: t.In(d.Location()), Value: c})
+		}
+	}
+
+	sort.Slice(events, func(i, j int) bool { return events[i].Time.Before(events[j].Time) })
+	return events
+}
+
+// Next returns the first crossing c after t. It reports false if the
+// body stays above or below the horizon.
+func Next(c Crossing, t time.Time, loc Location) (time.Time, bool) {
+	geopos := []float64{loc.Longitude, loc.Latitude, loc.Elevation}
+
+	var tret [10]float64
+	var errMsg [256]byte
+	switch swephgo.RiseTrans(jd.FromTimeUT(t), c.planet(), nil, 0, c.flag(), geopos, 0, 10, tret[:], errMsg[:]) {
+	case swephgo.Err:
+		panic(util.NTString(errMsg[:]))
+	case -2:
+		return time.Time{}, false
+	}
+	return jd.TimeUT(tret[0]), true
+}
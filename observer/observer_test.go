@@ -0,0 +1,52 @@
+package observer
+
+import (
+	"testing"
+	"time"
+)
+
+func TestLookup(t *testing.T) {
+	rome := Location{Name: "Rome", Latitude: 41.8933, Longitude: 12.4829, Elevation: 21}
+	for _, name := range []string{"Rome", "roma", " ROME ", "Roma, IT", "rome,it"} {
+		got, err := Lookup(name)
+		if err != nil {
+			t.Errorf("%q: %v", name, err)
+			continue
+		}
+		if got != rome {
+			t.Errorf("%q: got %+v, want %+v", name, got, rome)
+		}
+	}
+
+	for _, name := range []string{"Atlantis", "Rome, FR", ""} {
+		if got, err := Lookup(name); err == nil {
+			t.Errorf("%q: got %+v, want an error", name, got)
+		}
+	}
+}
+
+// TestPolar checks that the Sun is found neither rising nor setting in
+// the polar night and day of Svalbard, and the day has no crossings of it.
+func TestPolar(t *testing.T) {
+	svalbard := Location{Name: "Longyearbyen", Latitude: 78.22, Longitude: 15.65}
+	for _, d := range []time.Time{
+		time.Date(2024, time.June, 21, 0, 0, 0, 0, time.UTC),
+		time.Date(2024, time.December, 21, 0, 0, 0, 0, time.UTC),
+	} {
+		for _, c := range []Crossing{Sunrise, Sunset} {
+			if got, ok := Next(c, d, svalbard); ok {
+				t.Errorf("%s: got %s at %s, want none", d.Format(time.DateOnly), c, got)
+			}
+		}
+		for _, e := range ForDay(d, svalbard) {
+			if e.Value == Sunrise || e.Value == Sunset {
+				t.Errorf("%s: got %s at %s, want none", d.Format(time.DateOnly), e.Value, e.Time)
+			}
+		}
+	}
+
+	// and that it does rise in Rome
+	if _, ok := Next(Sunrise, time.Date(2024, time.June, 21, 0, 0, 0, 0, time.UTC), Location{Latitude: 41.89, Longitude: 12.48}); !ok {
+		t.Error("got no sunrise in Rome")
+	}
+}
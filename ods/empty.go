@@ -0,0 +1,25 @@
+package ods
+
+// The parts of an empty document, as made by New.
+
+const emptyContent = `<?xml version="1.0" encoding="UTF-8"?>
+<office:document-content` + namespaces + ` office:version="1.3"><office:automatic-styles/><office:body><office:spreadsheet/></office:body></office:document-content>`
+
+const emptyStyles = `<?xml version="1.0" encoding="UTF-8"?>
+<office:document-styles` + namespaces + ` office:version="1.3"><office:styles><style:default-style style:family="table-cell"><style:text-properties fo:language="en" fo:country="US"/></style:default-style><style:style style:name="Default" style:family="table-cell"/></office:styles></office:document-styles>`
+
+const emptyMeta = `<?xml version="1.0" encoding="UTF-8"?>
+<office:document-meta` + namespaces + ` office:version="1.3"><office:meta><meta:generator>mogo</meta:generator></office:meta></office:document-meta>`
+
+const namespaces = ` xmlns:office="urn:oasis:names:tc:opendocument:xmlns:office:1.0"` +
+	` xmlns:style="urn:oasis:names:tc:opendocument:xmlns:style:1.0"` +
+	` xmlns:text="urn:oasis:names:tc:opendocument:xmlns:text:1.0"` +
+	` xmlns:table="urn:oasis:names:tc:opendocument:xmlns:table:1.0"` +
+	` xmlns:number="urn:oasis:names:tc:opendocument:xmlns:datastyle:1.0"` +
+	` xmlns:fo="urn:oasis:names:tc:opendocument:xmlns:xsl-fo-compatible:1.0"` +
+	` xmlns:draw="urn:oasis:names:tc:opendocument:xmlns:drawing:1.0"` +
+	` xmlns:svg="urn:oasis:names:tc:opendocument:xmlns:svg-compatible:1.0"` +
+	` xmlns:xlink="http://www.w3.org/1999/xlink"` +
+	` xmlns:meta="urn:oasis:names:tc:opendocument:xmlns:meta:1.0"` +
+	` xmlns:dc="http://purl.org/dc/elements/1.1/"` +
+	` xmlns:calcext="urn:org:documentfoundation:names:experimental:calc:xmlns:calcext:1.0"`
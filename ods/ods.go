@@ -2,8 +2,12 @@ package ods
 
 import (
 	"archive/zip"
+	"errors"
 	"io"
+	"io/fs"
+	"mime"
 	"os"
+	"path"
 	"path/filepath"
 	"strconv"
 	"strings"
@@ -12,6 +16,7 @@ import (
 	"github.com/beevik/etree"
 	"github.com/mbolis/mogo/template"
 	"github.com/psanford/memfs"
+	"golang.org/x/text/language"
 )
 
 type Document struct {
@@ -19,15 +24,56 @@ type Document struct {
 	xml *etree.Document
 }
 
+// mimetype is the media type of spreadsheet documents.
+const mimetype = "application/vnd.oasis.opendocument.spreadsheet"
+
+const manifestPath = "META-INF/manifest.xml"
+
 func LoadTemplate() (*Document, error) {
+	files, err := zip.NewReader(template.ODS())
+	if err != nil {
+		return nil, err
+	}
+	return load(files)
+}
+
+// Open reads a document from the ODS file in r, size bytes long.
+func Open(r io.ReaderAt, size int64) (*Document, error) {
+	files, err := zip.NewReader(r, size)
+	if err != nil {
+		return nil, err
+	}
+	return load(files)
+}
+
+// New returns an empty document, with no sheets and only the default
+// cell style: sheets, rows and styles are to be added.
+func New() (*Document, error) {
 	fs := memfs.New()
+	for name, content := range map[string]string{
+		"mimetype":    mimetype,
+		"content.xml": emptyContent,
+		"styles.xml":  emptyStyles,
+		"meta.xml":    emptyMeta,
+	} {
+		err := fs.WriteFile(name, []byte(content), 0644)
+		if err != nil {
+			return nil, err
+		}
+	}
 
-	files, err := zip.NewReader(template.ODS())
+	doc, err := readDoc(fs)
 	if err != nil {
 		return nil, err
 	}
 
-	err = unzipInto(fs, files)
+	return &Document{fs, doc}, nil
+}
+
+func load(files *zip.Reader) (*Document, error) {
+	fs := memfs.New()
+
+	err := unzipInto(fs, files)
 	if err != nil {
 		return nil, err
 	}
@@ -83,40 +129,51 @@ func writeEntryInto(fs *memfs.FS, filename string, bytes []byte, mode os.FileMod
 }
 
 func readDoc(fs *memfs.FS) (*etree.Document, error) {
-	contentFile, err := fs.Open("content.xml")
+	return readXML(fs, "content.xml")
+}
+
+func readXML(fs *memfs.FS, filename string) (*etree.Document, error) {
+	file, err := fs.Open(filename)
 	if err != nil {
 		return nil, err
 	}
-	defer contentFile.Close()
+	defer file.Close()
 
 	doc := etree.NewDocument()
-	_, err = doc.ReadFrom(contentFile)
+	_, err = doc.ReadFrom(file)
 	if err != nil {
 		return nil, err
 	}
 	return doc, nil
 }
 
+// Row returns the r-th row of the first sheet, or nil.
 func (doc *Document) Row(r int) *Row {
-	xmlRows := doc.xml.FindElements("//table:table-row")
-	if len(xmlRows) <= r {
+	sheet := doc.Sheet(0)
+	if sheet == nil {
 		return nil
 	}
-	xml := xmlRows[r]
-	return &Row{xml}
+	return sheet.Row(r)
 }
 
 func (doc *Document) SetWorksheetName(i int, name string) {
-	sheets := doc.xml.FindElements("//table:table")
-	if len(sheets) <= i {
-		return
+	if sheet := doc.Sheet(i); sheet != nil {
+		sheet.SetName(name)
 	}
+}
 
-	sheet := sheets[i]
-	sheet.CreateAttr("table:name", name)
+// SetHeaderRows makes the first n rows of the first sheet header rows.
+func (doc *Document) SetHeaderRows(n int) {
+	if sheet := doc.Sheet(0); sheet != nil {
+		sheet.SetHeaderRows(n)
+	}
 }
 
 func (doc *Document) Write(out io.Writer) error {
+	if len(doc.Sheets()) == 0 {
+		return errors.New("the document has no sheets")
+	}
+
 	bytes, err := doc.xml.WriteToBytes()
 	if err != nil {
 		return err
@@ -125,36 +182,301 @@ func (doc *Document) Write(out io.Writer) error {
 	if err != nil {
 		return err
 	}
+	err = doc.writeManifest()
+	if err != nil {
+		return err
+	}
+
+	z := zip.NewWriter(out)
+	defer z.Close()
+
+	// the mimetype comes first and uncompressed, so that the type of the
+	// document can be told from its first bytes
+	w, err := z.CreateHeader(&zip.FileHeader{Name: "mimetype", Method: zip.Store})
+	if err != nil {
+		return err
+	}
+	_, err = io.WriteString(w, mimetype)
+	if err != nil {
+		return err
+	}
+
+	return fs.WalkDir(doc.fs, ".", func(name string, d fs.DirEntry, err error) error {
+		if err != nil || name == "." || name == "mimetype" {
+			return err
+		}
+		if d.IsDir() {
+			_, err := z.Create(name + "/")
+			return err
+		}
+		header := &zip.FileHeader{Name: name, Method: zip.Deflate}
+
+		bytes, err := fs.ReadFile(doc.fs, name)
+		if err != nil {
+			return err
+		}
+		w, err := z.CreateHeader(header)
+		if err != nil {
+			return err
+		}
+		_, err = w.Write(bytes)
+		return err
+	})
+}
+
+// writeManifest lists every file of the package in the manifest, with the
+// media types it already declared or else the ones of their extensions.
+func (doc *Document) writeManifest() error {
+	mediaTypes := make(map[string]string)
+	if old, err := readXML(doc.fs, manifestPath); err == nil {
+		for _, entry := range old.FindElements("//manifest:file-entry") {
+			mediaTypes[entry.SelectAttrValue("manifest:full-path", "")] = entry.SelectAttrValue("manifest:media-type", "")
+		}
+	}
+
+	manifest := etree.NewDocument()
+	manifest.CreateProcInst("xml", `version="1.0" encoding="UTF-8"`)
+	root := manifest.CreateElement("manifest:manifest")
+	root.CreateAttr("xmlns:manifest", "urn:oasis:names:tc:opendocument:xmlns:manifest:1.0")
+	root.CreateAttr("manifest:version", "1.3")
+
+	add := func(path, mediaType string) *etree.Element {
+		entry := root.CreateElement("manifest:file-entry")
+		entry.CreateAttr("manifest:full-path", path)
+		entry.CreateAttr("manifest:media-type", mediaType)
+		return entry
+	}
+	add("/", mimetype).CreateAttr("manifest:version", "1.3")
+
+	err := fs.WalkDir(doc.fs, ".", func(name string, d fs.DirEntry, err error) error {
+		switch {
+		case err != nil:
+			return err
+		case name == "." || name == "mimetype" || name == "META-INF" || name == manifestPath:
+			return nil
+		case d.IsDir():
+			// directories are listed only when they were, as the
+			// configurations of LibreOffice
+			if mediaType, ok := mediaTypes[name+"/"]; ok {
+				add(name+"/", mediaType)
+			}
+			return nil
+		}
+
+		mediaType, ok := mediaTypes[name]
+		if !ok {
+			mediaType = mediaTypeOf(name)
+		}
+		add(name, mediaType)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
 
-	zip := zip.NewWriter(out)
-	defer zip.Close()
-	return zip.AddFS(doc.fs)
+	bytes, err := manifest.WriteToBytes()
+	if err != nil {
+		return err
+	}
+	return writeEntryInto(doc.fs, manifestPath, bytes, 0644)
 }
 
-// SetHeaderRows moves the first n table rows into a
-// table:table-header-rows element so they are treated as
-// repeating header rows by Calc/LibreOffice when printing
-// or exporting to PDF.
-func (doc *Document) SetHeaderRows(n int) {
-	sheets := doc.xml.FindElements("//table:table")
-	if len(sheets) == 0 || n <= 0 {
-		return
+func mediaTypeOf(name string) string {
+	switch ext := path.Ext(name); ext {
+	case ".xml":
+		return "text/xml"
+	case ".rdf":
+		return "application/rdf+xml"
+	default:
+		if mediaType := mime.TypeByExtension(ext); mediaType != "" {
+			return mediaType
+		}
+		return "application/octet-stream"
 	}
+}
 
-	sheet := sheets[0]
-	rows := sheet.SelectElements("table:table-row")
-	if len(rows) < n {
-		return
+// SetLanguage sets the default language of the document, which
+// drives the month and weekday names shown in date cells.
+func (doc *Document) SetLanguage(lang language.Tag) error {
+	styles, err := readXML(doc.fs, "styles.xml")
+	if err != nil {
+		return err
 	}
 
-	hdr := etree.NewElement("table:table-header-rows")
-	for i := 0; i < n; i++ {
-		r := rows[i]
-		r.Parent().RemoveChild(r)
-		hdr.AddChild(r)
+	base, _ := lang.Base()
+	region, _ := lang.Region()
+	for _, props := range styles.FindElements("//style:text-properties[@fo:language]") {
+		props.CreateAttr("fo:language", base.String())
+		props.CreateAttr("fo:country", region.String())
 	}
 
-	sheet.InsertChildAt(0, hdr)
+	bytes, err := styles.WriteToBytes()
+	if err != nil {
+		return err
+	}
+	return doc.fs.WriteFile("styles.xml", bytes, 0)
+}
+
+// SetDescription sets the description in the properties of the document.
+func (doc *Document) SetDescription(description string) error {
+	meta, err := readXML(doc.fs, "meta.xml")
+	if err != nil {
+		return err
+	}
+
+	props := meta.FindElement("//office:meta")
+	desc := props.SelectElement("dc:description")
+	if desc == nil {
+		desc = props.CreateElement("dc:description")
+	}
+	desc.SetText(description)
+
+	bytes, err := meta.WriteToBytes()
+	if err != nil {
+		return err
+	}
+	return doc.fs.WriteFile("meta.xml", bytes, 0)
+}
+
+// AddImage stores an image in the document package, returning the
+// path used to reference it from cells. Images are stored only once.
+func (doc *Document) AddImage(name, mediaType string, data []byte) (string, error) {
+	href := "Pictures/" + name
+	if _, err := doc.fs.Open(href); err == nil {
+		return href, nil
+	}
+
+	err := writeEntryInto(doc.fs, href, data, 0644)
+	if err != nil {
+		return "", err
+	}
+
+	manifest, err := readXML(doc.fs, manifestPath)
+	if err != nil {
+		// the manifest is written with the document
+		return href, nil
+	}
+
+	entry := manifest.Root().CreateElement("manifest:file-entry")
+	entry.CreateAttr("manifest:full-path", href)
+	entry.CreateAttr("manifest:media-type", mediaType)
+
+	bytes, err := manifest.WriteToBytes()
+	if err != nil {
+		return "", err
+	}
+	return href, doc.fs.WriteFile(manifestPath, bytes, 0)
+}
+
+// NumberFormat is the way numeric cells are displayed.
+type NumberFormat struct {
+	Decimals int
+	Percent  bool
+}
+
+func (f NumberFormat) name() string {
+	name := "Nmogo" + strconv.Itoa(f.Decimals)
+	if f.Percent {
+		name += "P"
+	}
+	return name
+}
+
+// NumberCellStyle returns an automatic cell style which is a copy of base
+// showing numbers in the given format, adding it if needed.
+func (doc *Document) NumberCellStyle(base string, format NumberFormat) string {
+	name := base + "-" + format.name()
+
+	styles := doc.xml.FindElement("//office:automatic-styles")
+	if styles.FindElement("style:style[@style:name='"+name+"']") != nil {
+		return name
+	}
+
+	if styles.FindElement("*[@style:name='"+format.name()+"']") == nil {
+		tag := "number:number-style"
+		if format.Percent {
+			tag = "number:percentage-style"
+		}
+		dataStyle := styles.CreateElement(tag)
+		dataStyle.CreateAttr("style:name", format.name())
+
+		number := dataStyle.CreateElement("number:number")
+		number.CreateAttr("number:decimal-places", strconv.Itoa(format.Decimals))
+		number.CreateAttr("number:min-decimal-places", strconv.Itoa(format.Decimals))
+		number.CreateAttr("number:min-integer-digits", "1")
+		if format.Percent {
+			dataStyle.CreateElement("number:text").SetText("%")
+		}
+	}
+
+	return doc.derivedStyle(base, name, format.name())
+}
+
+// TimeFormat is the way time cells are displayed.
+type TimeFormat struct {
+	Seconds bool
+	Suffix  string // text following the time, as in "14:05 +02:00"
+}
+
+func (f TimeFormat) name() string {
+	name := "Nmogo-hm"
+	if f.Seconds {
+		name += "s"
+	}
+	if f.Suffix != "" {
+		name += "-" + strings.Map(func(r rune) rune {
+			switch {
+			case r == '+':
+				return 'p'
+			case r == '-':
+				return 'm'
+			case '0' <= r && r <= '9', 'A' <= r && r <= 'Z', 'a' <= r && r <= 'z':
+				return r
+			}
+			return -1
+		}, f.Suffix)
+	}
+	return name
+}
+
+// TimeCellStyle returns an automatic cell style which is a copy of base
+// showing times in the given format, adding it if needed.
+func (doc *Document) TimeCellStyle(base string, format TimeFormat) string {
+	name := base + "-" + format.name()
+
+	styles := doc.xml.FindElement("//office:automatic-styles")
+	if styles.FindElement("style:style[@style:name='"+name+"']") != nil {
+		return name
+	}
+
+	if styles.FindElement("*[@style:name='"+format.name()+"']") == nil {
+		dataStyle := styles.CreateElement("number:time-style")
+		dataStyle.CreateAttr("style:name", format.name())
+		parts := []string{"number:hours", "number:minutes"}
+		if format.Seconds {
+			parts = append(parts, "number:seconds")
+		}
+		for i, part := range parts {
+			if i > 0 {
+				dataStyle.CreateElement("number:text").SetText(":")
+			}
+			dataStyle.CreateElement(part).CreateAttr("number:style", "long")
+		}
+		if format.Suffix != "" {
+			dataStyle.CreateElement("number:text").SetText(" " + format.Suffix)
+		}
+	}
+
+	return doc.derivedStyle(base, name, format.name())
+}
+
+// derivedStyle adds the cell style name, a copy of base with another data style.
+func (doc *Document) derivedStyle(base, name, dataStyle string) string {
+	style := doc.copyStyle(base, name)
+	style.CreateAttr("style:data-style-name", dataStyle)
+	doc.automaticStyles().AddChild(style)
+
+	return name
 }
 
 type Row struct {
@@ -186,6 +508,30 @@ func (row *Row) SetCellString(c int, value string) {
 	p.SetText(value)
 }
 
+func (row *Row) SetCellFloat(c int, value float64) {
+	cell := row.getCell(c)
+
+	cell.CreateAttr("office:value-type", "float")
+	cell.CreateAttr("calcext:value-type", "float")
+	cell.CreateAttr("office:value", strconv.FormatFloat(value, 'f', -1, 64))
+}
+
+func (row *Row) SetCellPercentage(c int, value float64) {
+	cell := row.getCell(c)
+
+	cell.CreateAttr("office:value-type", "percentage")
+	cell.CreateAttr("calcext:value-type", "percentage")
+	cell.CreateAttr("office:value", strconv.FormatFloat(value, 'f', -1, 64))
+}
+
+func (row *Row) SetCellBool(c int, value bool) {
+	cell := row.getCell(c)
+
+	cell.CreateAttr("office:value-type", "boolean")
+	cell.CreateAttr("calcext:value-type", "boolean")
+	cell.CreateAttr("office:boolean-value", strconv.FormatBool(value))
+}
+
 func (row *Row) SetCellDate(c int, value time.Time) {
 	cell := row.getCell(c)
 
@@ -196,6 +542,16 @@ func (row *Row) SetCellDate(c int, value time.Time) {
 	cell.CreateAttr("office:date-value", date)
 }
 
+func (row *Row) SetCellDateTime(c int, value time.Time) {
+	cell := row.getCell(c)
+
+	cell.CreateAttr("office:value-type", "date")
+	cell.CreateAttr("calcext:value-type", "date")
+
+	date := value.Format("2006-01-02T15:04:05")
+	cell.CreateAttr("office:date-value", date)
+}
+
 func (row *Row) SetCellTime(c int, value time.Time) {
 	cell := row.getCell(c)
 
@@ -206,37 +562,130 @@ func (row *Row) SetCellTime(c int, value time.Time) {
 	cell.CreateAttr("office:time-value", time)
 }
 
-func (row *Row) getCell(c int) *etree.Element {
-	cells := row.xml.SelectElements("table:table-cell")
-	for i := 0; i < len(cells); i++ {
-		cell := cells[i]
+// Image is a picture to be anchored to a cell; Hrefs are alternative
+// renditions of the same picture, in order of preference.
+type Image struct {
+	Name  string
+	Hrefs []string
+}
 
-		repeatAttr := cell.SelectAttr("table:number-columns-repeated")
-		if repeatAttr != nil {
-			repeat, err := strconv.Atoi(repeatAttr.Value)
-			if err != nil {
-				panic(err)
-			}
+// SetCellImages anchors images to cell c, side by side, each one
+// size centimeters wide and high.
+func (row *Row) SetCellImages(c int, images []Image, size float64) {
+	cell := row.getCell(c)
+	for i, img := range images {
+		frame := cell.CreateElement("draw:frame")
+		frame.CreateAttr("draw:z-index", "1")
+		frame.CreateAttr("draw:name", img.Name)
+		frame.CreateAttr("svg:width", cm(size))
+		frame.CreateAttr("svg:height", cm(size))
+		frame.CreateAttr("svg:x", cm(0.05+float64(i)*size))
+		frame.CreateAttr("svg:y", cm(0.02))
+
+		for _, href := range img.Hrefs {
+			image := frame.CreateElement("draw:image")
+			image.CreateAttr("xlink:href", href)
+			image.CreateAttr("xlink:type", "simple")
+			image.CreateAttr("xlink:show", "embed")
+			image.CreateAttr("xlink:actuate", "onLoad")
+		}
+
+		title := frame.CreateElement("svg:title")
+		title.SetText(img.Name)
+	}
+}
+
+func cm(v float64) string {
+	return strconv.FormatFloat(v, 'f', 3, 64) + "cm"
+}
 
-			if repeat > 1 {
-				cell.RemoveAttr("table:number-columns-repeated")
+func (row *Row) CellStyle(c int) string {
+	return row.getCell(c).SelectAttrValue("table:style-name", "")
+}
 
-				var additionalCells []*etree.Element
-				for i := 1; i < repeat; i++ {
-					c := cell.Copy()
-					additionalCells = append(additionalCells, c)
-					cell.Parent().InsertChildAt(cell.Index()+1, c)
-				}
+func (row *Row) SetCellStyle(c int, name string) {
+	row.getCell(c).CreateAttr("table:style-name", name)
+}
 
-				leadingCells := cells[:i+1]
-				trailingCells := cells[i+1:]
-				cells = append(leadingCells, additionalCells...)
-				cells = append(cells, trailingCells...)
-			}
+// getCell returns the cell of column c, covered or not, adding empty
+// cells to the row, and columns to the sheet, when it is shorter.
+func (row *Row) getCell(c int) *etree.Element {
+	i := 0
+	for _, cell := range row.cells() {
+		repeat := repeated(cell, "table:number-columns-repeated")
+		if c < i+repeat {
+			return splitRepeated(cell, "table:number-columns-repeated", c-i, repeat)
+		}
+		i += repeat
+	}
+
+	if c > i {
+		setRepeated(row.xml.CreateElement("table:table-cell"), "table:number-columns-repeated", c-i)
+	}
+	if sheet := row.sheet(); sheet != nil {
+		sheet.ensureColumns(c + 1)
+	}
+	return row.xml.CreateElement("table:table-cell")
+}
+
+func (row *Row) cells() []*etree.Element {
+	var cells []*etree.Element
+	for _, e := range row.xml.ChildElements() {
+		if e.Space == "table" && (e.Tag == "table-cell" || e.Tag == "covered-table-cell") {
+			cells = append(cells, e)
 		}
-		if i == c {
-			return cell
+	}
+	return cells
+}
+
+// sheet returns the table of the row, or nil if it is not in one.
+func (row *Row) sheet() *Sheet {
+	for e := row.xml.Parent(); e != nil; e = e.Parent() {
+		if e.FullTag() == "table:table" {
+			return &Sheet{xml: e}
 		}
 	}
 	return nil
 }
+
+// repeated returns the number of times e is repeated, as told by attr,
+// in documents built or already read by this package.
+func repeated(e *etree.Element, attr string) int {
+	repeat, err := parseRepeated(e, attr)
+	if err != nil {
+		panic(err)
+	}
+	return repeat
+}
+
+// splitRepeated breaks up a cell, a column or a row repeated n times by
+// attr, so that its k-th repetition becomes a standalone element, and
+// returns it.
+// Only the elements around it are kept as repeated elements, which avoids
+// expanding the thousands of empty cells trailing each template row.
+func splitRepeated(e *etree.Element, attr string, k, n int) *etree.Element {
+	if n <= 1 {
+		return e
+	}
+	e.RemoveAttr(attr)
+
+	parent := e.Parent()
+	if k > 0 {
+		before := e.Copy()
+		setRepeated(before, attr, k)
+		parent.InsertChildAt(e.Index(), before)
+	}
+	if after := n - k - 1; after > 0 {
+		rest := e.Copy()
+		setRepeated(rest, attr, after)
+		parent.InsertChildAt(e.Index()+1, rest)
+	}
+	return e
+}
+
+// setRepeated repeats a cell, a column or a row n times by attr.
+func setRepeated(e *etree.Element, attr string, n int) {
+	if n > 1 {
+		e.CreateAttr(attr, strconv.Itoa(n))
+	}
+}
@@ -0,0 +1,228 @@
+package ods
+
+import (
+	"archive/zip"
+	"bytes"
+	"io"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/beevik/etree"
+)
+
+func TestBuild(t *testing.T) {
+	doc, err := New()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	doc.AddSheet("Appointments")
+	doc.AddSheet("Scratch")
+	doc.SheetByName("Scratch").Remove()
+	sheet := doc.AddSheet("Totals")
+	if names := sheetNames(doc); len(names) != 2 || names[0] != "Appointments" || names[1] != "Totals" {
+		t.Fatalf("got sheets %v, want [Appointments Totals]", names)
+	}
+
+	bold := doc.CellStyle("", CellStyle{Bold: true, Background: "#ffcc00", Border: "0.06pt solid #000000"})
+	if again := doc.CellStyle("", CellStyle{Bold: true, Background: "#ffcc00", Border: "0.06pt solid #000000"}); again != bold {
+		t.Errorf("the same style was added twice, as %s and %s", bold, again)
+	}
+
+	header := sheet.AddRow()
+	header.SetCellString(0, "Total")
+	header.SetCellStyle(0, bold)
+	sheet.MergeCells(0, 0, 1, 2)
+	header.SetCellString(2, "Done")
+
+	row := sheet.AddRow()
+	row.SetCellFloat(0, 12)
+	row.SetCellPercentage(1, 0.25)
+	row.SetCellBool(2, true)
+	sheet.SetColumnWidth(1, 3.5)
+
+	var out bytes.Buffer
+	if err := doc.Write(&out); err != nil {
+		t.Fatal(err)
+	}
+
+	files, err := zip.NewReader(bytes.NewReader(out.Bytes()), int64(out.Len()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if first := files.File[0]; first.Name != "mimetype" || first.Method != zip.Store {
+		t.Errorf("the first entry is %s, method %d, want an uncompressed mimetype", first.Name, first.Method)
+	}
+
+	manifest := readEntry(t, files, "META-INF/manifest.xml")
+	for _, name := range []string{"/", "content.xml", "styles.xml", "meta.xml"} {
+		if manifest.FindElement("//manifest:file-entry[@manifest:full-path='"+name+"']") == nil {
+			t.Errorf("the manifest does not list %s", name)
+		}
+	}
+
+	content := readEntry(t, files, "content.xml")
+	tables := content.FindElements("//table:table")
+	if len(tables) != 2 {
+		t.Fatalf("got %d tables, want 2", len(tables))
+	}
+	rows := tables[1].SelectElements("table:table-row")
+	if len(rows) != 2 {
+		t.Fatalf("got %d rows, want 2", len(rows))
+	}
+
+	cells := rows[0].ChildElements()
+	if len(cells) != 3 || cells[0].SelectAttrValue("table:number-columns-spanned", "") != "2" || cells[1].Tag != "covered-table-cell" {
+		t.Errorf("the first two cells of the header are not merged")
+	}
+	if style := content.FindElement("//style:style[@style:name='" + bold + "']/style:text-properties"); style == nil || style.SelectAttrValue("fo:font-weight", "") != "bold" {
+		t.Errorf("the header style is not bold")
+	}
+
+	cells = rows[1].ChildElements()
+	for i, want := range []string{"float", "percentage", "boolean"} {
+		if got := cells[i].SelectAttrValue("office:value-type", ""); got != want {
+			t.Errorf("cell %d: got type %s, want %s", i, got, want)
+		}
+	}
+
+	columns := tables[1].SelectElements("table:table-column")
+	if len(columns) != 3 || columns[1].SelectAttrValue("table:style-name", "") != "co-mogo-3.500" {
+		t.Errorf("got %d column elements, the second one not 3.5cm wide", len(columns))
+	}
+}
+
+func TestWriteWithoutSheets(t *testing.T) {
+	doc, err := New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := doc.Write(io.Discard); err == nil {
+		t.Error("wrote a document without sheets")
+	}
+}
+
+func sheetNames(doc *Document) []string {
+	var names []string
+	for _, sheet := range doc.Sheets() {
+		names = append(names, sheet.Name())
+	}
+	return names
+}
+
+func readEntry(t *testing.T, files *zip.Reader, name string) *etree.Document {
+	f, err := files.Open(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	doc := etree.NewDocument()
+	if _, err := doc.ReadFrom(f); err != nil {
+		t.Fatal(err)
+	}
+	return doc
+}
+
+func TestValues(t *testing.T) {
+	doc, err := New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	row := doc.AddSheet("Sheet1").AddRow()
+	date := time.Date(2024, time.March, 14, 0, 0, 0, 0, time.UTC)
+	row.SetCellDate(0, date)
+	row.SetCellTime(1, date.Add(14*time.Hour+5*time.Minute))
+	row.SetCellString(3, "Haircut")
+	row.SetCellFloat(4, 2.5)
+	row.SetCellBool(5, true)
+	row.SetCellStyle(8, doc.CellStyle("", CellStyle{Bold: true}))
+
+	var out bytes.Buffer
+	if err := doc.Write(&out); err != nil {
+		t.Fatal(err)
+	}
+	doc, err = Open(bytes.NewReader(out.Bytes()), int64(out.Len()))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := doc.Sheet(0).Row(0).Values()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []any{date, 14*time.Hour + 5*time.Minute, nil, "Haircut", 2.5, true}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
+
+// TestRepeatedRows checks that repeated rows count, and are read, as many
+// times as they are repeated, as LibreOffice saves empty and equal rows.
+func TestRepeatedRows(t *testing.T) {
+	doc, err := New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	sheet := doc.AddSheet("Sheet1")
+	sheet.AddRow().SetCellString(0, "Date")
+	setRepeated(sheet.AddRow().xml, "table:number-rows-repeated", 2)
+	row := sheet.AddRow()
+	row.SetCellString(0, "Haircut")
+	setRepeated(row.xml, "table:number-rows-repeated", 3)
+	sheet.AddRow().SetCellString(0, "Waxing")
+	setRepeated(sheet.AddRow().xml, "table:number-rows-repeated", 1000)
+
+	if got, want := sheet.RowCount(), 1007; got != want {
+		t.Errorf("got %d rows, want %d", got, want)
+	}
+	got, err := sheet.Values()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := [][]any{{"Date"}, nil, nil, {"Haircut"}, {"Haircut"}, {"Haircut"}, {"Waxing"}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+
+	sheet.Row(4).SetCellString(1, "good")
+	if got, want := sheet.RowCount(), 1007; got != want {
+		t.Errorf("got %d rows after a write, want %d", got, want)
+	}
+	for r, want := range []int{1, 2, 1} {
+		if values, _ := sheet.Row(3 + r).Values(); len(values) != want {
+			t.Errorf("row %d: got %v, want %d values", 3+r, values, want)
+		}
+	}
+}
+
+// TestMalformedRepeated checks that the repetitions of the rows and the
+// cells of a file are read as errors, rather than panics, if malformed.
+func TestMalformedRepeated(t *testing.T) {
+	for _, tt := range []struct {
+		attr string
+		want string
+	}{
+		{"table:number-rows-repeated", "row 2: invalid table:number-rows-repeated 'x'"},
+		{"table:number-columns-repeated", "row 2: column 1: invalid table:number-columns-repeated 'x'"},
+	} {
+		doc, err := New()
+		if err != nil {
+			t.Fatal(err)
+		}
+		sheet := doc.AddSheet("Sheet1")
+		sheet.AddRow().SetCellString(0, "Date")
+		row := sheet.AddRow()
+		row.SetCellString(0, "Haircut")
+		target := row.xml
+		if tt.attr == "table:number-columns-repeated" {
+			target = row.cells()[0]
+		}
+		target.CreateAttr(tt.attr, "x")
+
+		if _, err := sheet.Values(); err == nil || err.Error() != tt.want {
+			t.Errorf("%s: got error %v, want %s", tt.attr, err, tt.want)
+		}
+	}
+}
@@ -0,0 +1,185 @@
+package ods
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/beevik/etree"
+)
+
+// RowCount returns the number of rows of the sheet, counting repeated
+// rows as many times as they are repeated.
+func (sheet *Sheet) RowCount() int {
+	count := 0
+	for _, row := range sheet.rows() {
+		count += repeated(row, "table:number-rows-repeated")
+	}
+	return count
+}
+
+// Values returns the values of the rows of the sheet, as Row.Values does,
+// up to the last one which is not empty. Repeated rows are read once and
+// repeated in the result, so that the r-th one is that of Row(r).
+func (sheet *Sheet) Values() ([][]any, error) {
+	var rows [][]any
+	empty := 0
+	for _, row := range sheet.rows() {
+		values, err := (&Row{row}).Values()
+		if err != nil {
+			return nil, fmt.Errorf("row %d: %w", len(rows)+empty+1, err)
+		}
+
+		repeat, err := parseRepeated(row, "table:number-rows-repeated")
+		if err != nil {
+			return nil, fmt.Errorf("row %d: %w", len(rows)+empty+1, err)
+		}
+		if len(values) == 0 {
+			// empty rows count only before a value, such as the
+			// million ones trailing the sheets saved by LibreOffice
+			empty += repeat
+			continue
+		}
+		for ; empty > 0; empty-- {
+			rows = append(rows, nil)
+		}
+		for i := 0; i < repeat; i++ {
+			rows = append(rows, values)
+		}
+	}
+	return rows, nil
+}
+
+// Values returns the values of the cells of the row, up to the last one
+// which is not empty: strings, float64 for numbers and percentages, bool,
+// time.Time for dates, in UTC, time.Duration for times, and nil for empty
+// cells.
+func (row *Row) Values() ([]any, error) {
+	var values []any
+	empty := 0
+	for _, cell := range row.cells() {
+		value, err := cellValue(cell)
+		if err != nil {
+			return nil, fmt.Errorf("column %d: %w", len(values)+empty+1, err)
+		}
+
+		repeat, err := parseRepeated(cell, "table:number-columns-repeated")
+		if err != nil {
+			return nil, fmt.Errorf("column %d: %w", len(values)+empty+1, err)
+		}
+		if value == nil {
+			// empty cells count only before a value
+			empty += repeat
+			continue
+		}
+		for ; empty > 0; empty-- {
+			values = append(values, nil)
+		}
+		for i := 0; i < repeat; i++ {
+			values = append(values, value)
+		}
+	}
+	return values, nil
+}
+
+func cellValue(cell *etree.Element) (any, error) {
+	switch cell.SelectAttrValue("office:value-type", "") {
+	case "float", "percentage", "currency":
+		return strconv.ParseFloat(cell.SelectAttrValue("office:value", ""), 64)
+	case "boolean":
+		return cell.SelectAttrValue("office:boolean-value", "") == "true", nil
+	case "date":
+		return time.Parse("2006-01-02T15:04:05", expandDate(cell.SelectAttrValue("office:date-value", "")))
+	case "time":
+		return parseDuration(cell.SelectAttrValue("office:time-value", ""))
+	}
+
+	text, err := cellText(cell)
+	if text == "" || err != nil {
+		return nil, err
+	}
+	return text, nil
+}
+
+// expandDate gives a time to the date values without one.
+func expandDate(s string) string {
+	if !strings.Contains(s, "T") {
+		return s + "T00:00:00"
+	}
+	return s
+}
+
+var durationRegex = regexp.MustCompile(`^PT(\d+)H(\d+)M(\d+(?:\.\d+)?)S$`)
+
+// parseDuration parses the times of day of cells, as in "PT14H05M00S".
+func parseDuration(s string) (time.Duration, error) {
+	m := durationRegex.FindStringSubmatch(s)
+	if m == nil {
+		return 0, fmt.Errorf("invalid time '%s'", s)
+	}
+	hours, _ := strconv.Atoi(m[1])
+	minutes, _ := strconv.Atoi(m[2])
+	seconds, _ := strconv.ParseFloat(m[3], 64)
+	return time.Duration(hours)*time.Hour + time.Duration(minutes)*time.Minute +
+		time.Duration(seconds*float64(time.Second)), nil
+}
+
+// parseRepeated returns the number of times e is repeated, as told by attr,
+// or an error if attr is not a positive number.
+func parseRepeated(e *etree.Element, attr string) (int, error) {
+	repeatAttr := e.SelectAttr(attr)
+	if repeatAttr == nil {
+		return 1, nil
+	}
+	repeat, err := strconv.Atoi(repeatAttr.Value)
+	if err != nil || repeat < 1 {
+		return 0, fmt.Errorf("invalid %s '%s'", attr, repeatAttr.Value)
+	}
+	return repeat, nil
+}
+
+// cellText returns the paragraphs of a cell, one per line.
+func cellText(cell *etree.Element) (string, error) {
+	var lines []string
+	for _, p := range cell.SelectElements("text:p") {
+		line, err := elementText(p)
+		if err != nil {
+			return "", err
+		}
+		lines = append(lines, line)
+	}
+	return strings.Join(lines, "\n"), nil
+}
+
+// elementText returns the text of e and its descendants, such as spans.
+func elementText(e *etree.Element) (string, error) {
+	var b strings.Builder
+	for _, token := range e.Child {
+		switch t := token.(type) {
+		case *etree.CharData:
+			b.WriteString(t.Data)
+		case *etree.Element:
+			switch t.FullTag() {
+			case "text:s":
+				spaces, err := parseRepeated(t, "text:c")
+				if err != nil {
+					return "", err
+				}
+				b.WriteString(strings.Repeat(" ", spaces))
+			case "text:tab":
+				b.WriteString("\t")
+			case "text:line-break":
+				b.WriteString("\n")
+			default:
+				text, err := elementText(t)
+				if err != nil {
+					return "", err
+				}
+				b.WriteString(text)
+			}
+		}
+	}
+	return b.String(), nil
+}
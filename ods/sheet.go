@@ -0,0 +1,197 @@
+package ods
+
+import (
+	"strconv"
+
+	"github.com/beevik/etree"
+)
+
+// Sheet is a table of the document.
+type Sheet struct {
+	doc *Document
+	xml *etree.Element
+}
+
+func (doc *Document) spreadsheet() *etree.Element {
+	return doc.xml.FindElement("//office:spreadsheet")
+}
+
+func (doc *Document) Sheets() []*Sheet {
+	var sheets []*Sheet
+	for _, xml := range doc.spreadsheet().SelectElements("table:table") {
+		sheets = append(sheets, &Sheet{doc, xml})
+	}
+	return sheets
+}
+
+// Sheet returns the i-th sheet, or nil.
+func (doc *Document) Sheet(i int) *Sheet {
+	sheets := doc.Sheets()
+	if i < 0 || len(sheets) <= i {
+		return nil
+	}
+	return sheets[i]
+}
+
+// SheetByName returns the sheet called name, or nil.
+func (doc *Document) SheetByName(name string) *Sheet {
+	for _, sheet := range doc.Sheets() {
+		if sheet.Name() == name {
+			return sheet
+		}
+	}
+	return nil
+}
+
+// AddSheet adds an empty sheet called name after the last one.
+func (doc *Document) AddSheet(name string) *Sheet {
+	xml := etree.NewElement("table:table")
+	xml.CreateAttr("table:name", name)
+	column := xml.CreateElement("table:table-column")
+	column.CreateAttr("table:default-cell-style-name", "Default")
+
+	// sheets come before the named expressions and the database ranges
+	spreadsheet := doc.spreadsheet()
+	index := len(spreadsheet.Child)
+	if sheets := doc.Sheets(); len(sheets) > 0 {
+		index = sheets[len(sheets)-1].xml.Index() + 1
+	} else {
+		for _, e := range spreadsheet.ChildElements() {
+			if e.Space == "table" && e.Tag != "calculation-settings" && e.Tag != "content-validations" && e.Tag != "label-ranges" {
+				index = e.Index()
+				break
+			}
+		}
+	}
+	spreadsheet.InsertChildAt(index, xml)
+
+	return &Sheet{doc, xml}
+}
+
+// Remove takes the sheet out of the document.
+func (sheet *Sheet) Remove() {
+	sheet.xml.Parent().RemoveChild(sheet.xml)
+}
+
+func (sheet *Sheet) Name() string {
+	return sheet.xml.SelectAttrValue("table:name", "")
+}
+
+func (sheet *Sheet) SetName(name string) {
+	sheet.xml.CreateAttr("table:name", name)
+}
+
+func (sheet *Sheet) rows() []*etree.Element {
+	return sheet.xml.FindElements(".//table:table-row")
+}
+
+// Row returns the r-th row of the sheet, split from the repeated ones,
+// or nil.
+func (sheet *Sheet) Row(r int) *Row {
+	if r < 0 {
+		return nil
+	}
+	i := 0
+	for _, row := range sheet.rows() {
+		repeat := repeated(row, "table:number-rows-repeated")
+		if r < i+repeat {
+			return &Row{splitRepeated(row, "table:number-rows-repeated", r-i, repeat)}
+		}
+		i += repeat
+	}
+	return nil
+}
+
+// AddRow appends an empty row to the sheet.
+func (sheet *Sheet) AddRow() *Row {
+	return &Row{sheet.xml.CreateElement("table:table-row")}
+}
+
+// SetHeaderRows moves the first n table rows into a
+// table:table-header-rows element so they are treated as
+// repeating header rows by Calc/LibreOffice when printing
+// or exporting to PDF.
+func (sheet *Sheet) SetHeaderRows(n int) {
+	rows := sheet.xml.SelectElements("table:table-row")
+	if n <= 0 || len(rows) < n {
+		return
+	}
+
+	index := rows[0].Index()
+	hdr := etree.NewElement("table:table-header-rows")
+	for i := 0; i < n; i++ {
+		r := rows[i]
+		r.Parent().RemoveChild(r)
+		hdr.AddChild(r)
+	}
+
+	sheet.xml.InsertChildAt(index, hdr)
+}
+
+// SetColumnWidth sets the width of column c, in centimeters.
+func (sheet *Sheet) SetColumnWidth(c int, width float64) {
+	sheet.ensureColumns(c + 1)
+	column := sheet.column(c)
+	column.CreateAttr("table:style-name", sheet.doc.columnStyle(width))
+}
+
+// MergeCells merges the cells of rows rows and cols columns from row r
+// and column c into one, which is the one at r and c: the others are
+// covered by it. Missing rows are added.
+func (sheet *Sheet) MergeCells(r, c, rows, cols int) {
+	for sheet.RowCount() < r+rows {
+		sheet.AddRow()
+	}
+
+	for i := r; i < r+rows; i++ {
+		row := sheet.Row(i)
+		for j := c; j < c+cols; j++ {
+			cell := row.getCell(j)
+			if i == r && j == c {
+				cell.CreateAttr("table:number-rows-spanned", strconv.Itoa(rows))
+				cell.CreateAttr("table:number-columns-spanned", strconv.Itoa(cols))
+				continue
+			}
+			cell.Tag = "covered-table-cell"
+		}
+	}
+}
+
+func (sheet *Sheet) columns() []*etree.Element {
+	return sheet.xml.FindElements(".//table:table-column")
+}
+
+// column returns the element of column c, split from the repeated ones.
+func (sheet *Sheet) column(c int) *etree.Element {
+	i := 0
+	for _, column := range sheet.columns() {
+		repeat := repeated(column, "table:number-columns-repeated")
+		if c < i+repeat {
+			return splitRepeated(column, "table:number-columns-repeated", c-i, repeat)
+		}
+		i += repeat
+	}
+	return nil
+}
+
+// ensureColumns adds default columns until the sheet has n.
+func (sheet *Sheet) ensureColumns(n int) {
+	columns := sheet.columns()
+	count := 0
+	for _, column := range columns {
+		count += repeated(column, "table:number-columns-repeated")
+	}
+	if count >= n {
+		return
+	}
+
+	column := etree.NewElement("table:table-column")
+	column.CreateAttr("table:default-cell-style-name", "Default")
+	setRepeated(column, "table:number-columns-repeated", n-count)
+	if len(columns) == 0 {
+		sheet.xml.InsertChildAt(0, column)
+		return
+	}
+	last := columns[len(columns)-1]
+	last.Parent().InsertChildAt(last.Index()+1, column)
+}
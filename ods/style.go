@@ -0,0 +1,115 @@
+package ods
+
+import (
+	"fmt"
+	"hash/fnv"
+	"strconv"
+
+	"github.com/beevik/etree"
+)
+
+// CellStyle is the look of cells, on top of the one of a base style.
+// Colors are as in "#ffcc00" and borders as in "0.06pt solid #000000";
+// the empty ones are those of the base style.
+type CellStyle struct {
+	Bold       bool
+	Background string
+	Border     string
+}
+
+func (s CellStyle) name() string {
+	h := fnv.New32a()
+	fmt.Fprintf(h, "%v", s)
+	return fmt.Sprintf("Cmogo%08x", h.Sum32())
+}
+
+// CellStyle returns an automatic cell style which is a copy of base,
+// or "Default" if empty, with the look of s, adding it if needed.
+func (doc *Document) CellStyle(base string, s CellStyle) string {
+	if base == "" {
+		base = "Default"
+	}
+	name := base + "-" + s.name()
+
+	styles := doc.automaticStyles()
+	if styles.FindElement("style:style[@style:name='"+name+"']") != nil {
+		return name
+	}
+
+	style := doc.copyStyle(base, name)
+	if s.Background != "" || s.Border != "" {
+		props := child(style, "style:table-cell-properties", true)
+		if s.Background != "" {
+			props.CreateAttr("fo:background-color", s.Background)
+		}
+		if s.Border != "" {
+			for _, side := range []string{"fo:border-top", "fo:border-bottom", "fo:border-left", "fo:border-right"} {
+				props.RemoveAttr(side)
+			}
+			props.CreateAttr("fo:border", s.Border)
+		}
+	}
+	if s.Bold {
+		props := child(style, "style:text-properties", false)
+		for _, attr := range []string{"fo:font-weight", "style:font-weight-asian", "style:font-weight-complex"} {
+			props.CreateAttr(attr, "bold")
+		}
+	}
+	styles.AddChild(style)
+
+	return name
+}
+
+// columnStyle returns an automatic column style width centimeters wide,
+// adding it if needed.
+func (doc *Document) columnStyle(width float64) string {
+	name := "co-mogo-" + strconv.FormatFloat(width, 'f', 3, 64)
+
+	styles := doc.automaticStyles()
+	if styles.FindElement("style:style[@style:name='"+name+"']") != nil {
+		return name
+	}
+
+	style := styles.CreateElement("style:style")
+	style.CreateAttr("style:name", name)
+	style.CreateAttr("style:family", "table-column")
+	props := style.CreateElement("style:table-column-properties")
+	props.CreateAttr("fo:break-before", "auto")
+	props.CreateAttr("style:column-width", cm(width))
+
+	return name
+}
+
+func (doc *Document) automaticStyles() *etree.Element {
+	return doc.xml.FindElement("//office:automatic-styles")
+}
+
+// copyStyle returns a new cell style name, a copy of base if it is an
+// automatic style, else inheriting from it.
+func (doc *Document) copyStyle(base, name string) *etree.Element {
+	var style *etree.Element
+	if baseStyle := doc.automaticStyles().FindElement("style:style[@style:name='" + base + "']"); baseStyle != nil {
+		style = baseStyle.Copy()
+	} else {
+		style = etree.NewElement("style:style")
+		style.CreateAttr("style:family", "table-cell")
+		style.CreateAttr("style:parent-style-name", base)
+	}
+	style.CreateAttr("style:name", name)
+	return style
+}
+
+// child returns the child tag of e, adding it first or last if missing:
+// the properties of styles go in a fixed order.
+func child(e *etree.Element, tag string, first bool) *etree.Element {
+	if c := e.SelectElement(tag); c != nil {
+		return c
+	}
+	c := etree.NewElement(tag)
+	if first {
+		e.InsertChildAt(0, c)
+	} else {
+		e.AddChild(c)
+	}
+	return c
+}
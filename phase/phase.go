@@ -5,14 +5,19 @@ import (
 	"math"
 	"time"
 
+	"github.com/mbolis/mogo/cache"
 	"github.com/mbolis/mogo/jd"
 	"github.com/mbolis/mogo/model"
 	"github.com/mbolis/mogo/position"
+	"github.com/mbolis/mogo/search"
 	"github.com/mshafiee/swephgo"
 )
 
 type Value struct {
 	Ph, JD float64
+
+	// Illumination is the illuminated fraction of the Moon's disc
+	Illumination float64
 }
 
 func (e Value) Time() time.Time {
@@ -41,6 +46,30 @@ func (e Value) Phase() Phase {
 	panic(fmt.Sprintf("impossible phase: %f", e.Ph))
 }
 
+// SynodicMonth is the mean duration of a lunation, in days.
+const SynodicMonth = 29.530588853
+
+// Age returns the days elapsed since the last new moon.
+func (e Value) Age() float64 {
+	if e.Ph == 0 {
+		return 0
+	}
+
+	elapsed := e.Ph
+	if elapsed < 0 {
+		elapsed += 360
+	}
+
+	// the elongation cannot have grown slower than at its minimum rate:
+	// the last new moon is the last crossing of 0° since then
+	from := e.JD - elapsed/Elongation.MinRate - 1
+	crossings := Elongation.Crossings([]float64{0}, from, e.JD)
+	if len(crossings) == 0 {
+		panic(fmt.Sprintf("no new moon before %f", e.JD))
+	}
+	return e.JD - crossings[len(crossings)-1].JD
+}
+
 func Calc(jd float64) Value {
 	return calc(jd, false)
 }
@@ -62,13 +91,25 @@ func calc(jd float64, ut bool) Value {
 		calcPos = position.Calc
 	}
 
-	sunLon := calcPos(jd, swephgo.SeSun).Longitude
-	moonLon := calcPos(jd, swephgo.SeMoon).Longitude
+	sun := calcPos(jd, swephgo.SeSun)
+	moon := calcPos(jd, swephgo.SeMoon)
 	return Value{
-		JD: jd,
-		Ph: normDeg180(moonLon - sunLon),
+		JD:           jd,
+		Ph:           normDeg180(moon.Longitude - sun.Longitude),
+		Illumination: illumination(sun, moon),
 	}
 }
+
+// illumination computes the illuminated fraction from the phase angle
+// (Meeus, Astronomical Algorithms, 48.2 and 48.3).
+func illumination(sun, moon position.Position) float64 {
+	const rad = math.Pi / 180
+
+	// distances are in AU
+	elongation := math.Acos(math.Cos(moon.Latitude*rad) * math.Cos((moon.Longitude-sun.Longitude)*rad))
+	phaseAngle := math.Atan2(sun.Distance*math.Sin(elongation), moon.Distance-sun.Distance*math.Cos(elongation))
+	return (1 + math.Cos(phaseAngle)) / 2
+}
 func normDeg180(th float64) float64 {
 	th = math.Mod(th, 360)
 	if th < 0 {
@@ -119,71 +160,61 @@ func (p Phase) String() string {
 	}
 }
 
-var phaseCache = make(map[float64]Value)
+// phaseCache keeps the day boundaries, shared by consecutive days.
+var phaseCache = cache.New[float64, Value](4096)
 
 func calcCached(d float64) Value {
-	if ph, ok := phaseCache[d]; ok {
-		return ph
-	}
-
-	ph := Calc(d)
-	phaseCache[d] = ph
-	return ph
+	return phaseCache.GetOrCompute(d, Calc)
 }
 
-func ForDay(d time.Time) (dv model.DailyValue[Phase]) {
-	d0 := time.Date(d.Year(), d.Month(), d.Day(), 0, 0, 0, 0, d.Location()).In(time.UTC)
-	ph0 := calcCached(jd.FromTime(d0))
-	ph1 := calcCached(jd.FromTime(d0.AddDate(0, 0, 1)))
-
-	dv.Curr = ph0.Phase()
-	dv.Next = ph1.Phase()
-
-	switch {
-	case ph0.Ph <= 0 && ph1.Ph > 0:
-		dv.Event = New.binarySearch(ph0, ph1)
-		dv.Event.Time = dv.Event.Time.In(d.Location())
-	case ph0.Ph > 0 && ph1.Ph < 0:
-		dv.Event = Full.binarySearch(ph0, ph1)
-		dv.Event.Time = dv.Event.Time.In(d.Location())
-	}
-
-	return
+// Elongation is the angle of the Moon from the Sun along the ecliptic,
+// growing by 10° to 15° a day.
+var Elongation = search.Angle{
+	At: func(d float64) float64 {
+		ph := Calc(d).Ph
+		if ph < 0 {
+			ph += 360
+		}
+		return ph
+	},
+	MinRate:  10,
+	MeanRate: 360 / SynodicMonth,
+	MaxRate:  15,
 }
 
-func (et Phase) binarySearch(start, end Value) *model.Event[Phase] {
-	for {
-		mid := calcCached(start.JD + (end.JD-start.JD)/2)
-		start, end = et.selectNext(start, mid, end)
-		if end.JD-start.JD < jd.HalfMinute {
-			return &model.Event[Phase]{
-				Time:  end.Time(),
-				Value: et,
-			}
-		}
+// Events returns the new and full moons from start to end.
+func Events(start, end time.Time) []model.Event[Phase] {
+	var events []model.Event[Phase]
+	for _, c := range Elongation.Crossings([]float64{0, 180}, jd.FromTime(start), jd.FromTime(end)) {
+		events = append(events, model.Event[Phase]{
+			Time:  jd.Time(c.JD).In(start.Location()),
+			Value: [...]Phase{New, Full}[c.Target],
+		})
 	}
+	return events
 }
-func (et Phase) selectNext(start, mid, end Value) (Value, Value) {
-	switch et {
-	case New:
-		switch {
-		case mid.Ph < 0:
-			return mid, end
-		case mid.Ph == 0:
-			return mid, mid
-		case mid.Ph > 0:
-			return start, mid
+
+// ForDays computes the phases of the days from start to end, which must
+// be day starts, searching the events over the whole range at once.
+func ForDays(start, end time.Time) []model.DailyValue[Phase] {
+	events := Events(start, end)
+
+	var dvs []model.DailyValue[Phase]
+	for d := start; d.Before(end); d = model.NextDay(d) {
+		next := model.NextDay(d)
+		dv := model.DailyValue[Phase]{
+			Curr: calcCached(jd.FromTime(d)).Phase(),
+			Next: calcCached(jd.FromTime(next)).Phase(),
 		}
-	case Full:
-		switch {
-		case mid.Ph < 0:
-			return start, mid
-		case mid.Ph == 0:
-			return mid, mid
-		case mid.Ph > 0:
-			return mid, end
+		for len(events) > 0 && events[0].Time.Before(next) {
+			dv.Events = append(dv.Events, events[0])
+			events = events[1:]
 		}
+		dvs = append(dvs, dv)
 	}
+	return dvs
+}
 
-	panic(fmt.Sprintf("unrecognized EventType: %d", et))
+func ForDay(d time.Time) model.DailyValue[Phase] {
+	return ForDays(model.DayStart(d), model.NextDay(d))[0]
 }
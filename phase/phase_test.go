@@ -0,0 +1,118 @@
+package phase
+
+import (
+	"encoding/csv"
+	"math"
+	"math/rand"
+	"os"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+)
+
+// tolerance covers the half minute the published times are rounded by and
+// the second the search of the crossings stops at.
+const tolerance = time.Minute/2 + time.Second
+
+func TestForDayGolden(t *testing.T) {
+	f, err := os.Open("testdata/phases.csv")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	r := csv.NewReader(f)
+	r.Comment = '#'
+	records, err := r.ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, record := range records[1:] {
+		want, err := time.Parse("2006-01-02T15:04Z", record[0])
+		if err != nil {
+			t.Fatal(err)
+		}
+		ph := map[string]Phase{"new": New, "full": Full}[record[1]]
+
+		dv := ForDay(want)
+		if len(dv.Events) != 1 {
+			t.Errorf("%s: got events %v, want %s moon", record[0], dv.Events, record[1])
+			continue
+		}
+		e := dv.Events[0]
+		if e.Value != ph {
+			t.Errorf("%s: got %s moon, want %s", record[0], e.Value, record[1])
+		}
+		if diff := e.Time.Sub(want).Abs(); diff > tolerance {
+			t.Errorf("%s: got %s moon at %s, %s off", record[0], record[1], e.Time.Format(time.RFC3339), diff)
+		}
+	}
+}
+
+func TestAge(t *testing.T) {
+	published := time.Date(2024, time.January, 11, 11, 57, 0, 0, time.UTC)
+	events := Events(published.Add(-time.Hour), published.Add(time.Hour))
+	if len(events) != 1 || events[0].Value != New {
+		t.Fatalf("got events %v, want the new moon of %s", events, published)
+	}
+	newMoon := events[0].Time
+
+	// waxing and waning, across the full moon
+	for _, days := range []float64{3, 10, 20, 29} {
+		ph := CalcTime(newMoon.Add(time.Duration(days * 24 * float64(time.Hour))))
+		if age := ph.Age(); math.Abs(age-days) > 2.0/86400 {
+			t.Errorf("%g days after the new moon, %s: got age %f", days, ph.Phase(), age)
+		}
+	}
+}
+
+func TestNormDeg180(t *testing.T) {
+	rnd := rand.New(rand.NewSource(1))
+	for i := 0; i < 10000; i++ {
+		th := (rnd.Float64() - 0.5) * 4000
+		n := normDeg180(th)
+		if n < -180 || n >= 180 {
+			t.Fatalf("normDeg180(%f) = %f, out of [-180, 180)", th, n)
+		}
+		if k := (th - n) / 360; math.Abs(k-math.Round(k)) > 1e-9 {
+			t.Fatalf("normDeg180(%f) = %f, not a whole number of turns apart", th, n)
+		}
+	}
+
+	for th, want := range map[float64]float64{0: 0, 180: -180, -180: -180, 360: 0, 540: -180, 179.5: 179.5, -0.5: -0.5} {
+		if got := normDeg180(th); got != want {
+			t.Errorf("normDeg180(%f) = %f, want %f", th, got, want)
+		}
+	}
+}
+
+// TestForDayConcurrent is meant to be run with the race detector.
+func TestForDayConcurrent(t *testing.T) {
+	start := time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+	var want []string
+	for i := 0; i < 60; i++ {
+		want = append(want, ForDay(start.AddDate(0, 0, i)).String())
+	}
+
+	var wg sync.WaitGroup
+	results := make([][]string, 8)
+	for g := range results {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			for i := 0; i < 60; i++ {
+				results[g] = append(results[g], ForDay(start.AddDate(0, 0, i)).String())
+			}
+		}(g)
+	}
+	wg.Wait()
+
+	for g, got := range results {
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("goroutine %d: got %v, want %v", g, got, want)
+		}
+	}
+}
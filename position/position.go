@@ -28,6 +28,9 @@ func CalcTime(d time.Time, planet int) Position {
 	return calc(jd, false, planet)
 }
 
+// calc makes a single call to the Swiss Ephemeris, which keeps global state:
+// swephgo serializes its calls, so it is safe as long as no setting (such
+// as swe_set_topo) has to hold across several calls.
 func calc(jd float64, ut bool, planet int) Position {
 	var calc func(jd float64, pl int, flag int, xx []float64, err []byte) int32
 	if ut {
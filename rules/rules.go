@@ -0,0 +1,258 @@
+package rules
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	"github.com/mbolis/mogo/phase"
+	"github.com/mbolis/mogo/sign"
+	"github.com/mbolis/mogo/status"
+)
+
+var phasesByKey = map[string][]phase.Phase{
+	"new":             {phase.New},
+	"waxing":          {phase.Waxing1, phase.Waxing2, phase.Waxing3},
+	"waxing-crescent": {phase.Waxing1},
+	"first-quarter":   {phase.Waxing2},
+	"waxing-gibbous":  {phase.Waxing3},
+	"full":            {phase.Full},
+	"waning":          {phase.Waning1, phase.Waning2, phase.Waning3},
+	"waning-gibbous":  {phase.Waning1},
+	"last-quarter":    {phase.Waning2},
+	"waning-crescent": {phase.Waning3},
+}
+
+var signsByKey = map[string]sign.Sign{
+	"aries":       sign.Aries,
+	"taurus":      sign.Taurus,
+	"gemini":      sign.Gemini,
+	"cancer":      sign.Cancer,
+	"leo":         sign.Leo,
+	"virgo":       sign.Virgo,
+	"libra":       sign.Libra,
+	"scorpio":     sign.Scorpio,
+	"sagittarius": sign.Sagittarius,
+	"capricorn":   sign.Capricorn,
+	"aquarius":    sign.Aquarius,
+	"pisces":      sign.Pisces,
+	"ophiuchus":   sign.Ophiuchus,
+}
+
+var weekdaysByKey = map[string]time.Weekday{
+	"sunday":    time.Sunday,
+	"monday":    time.Monday,
+	"tuesday":   time.Tuesday,
+	"wednesday": time.Wednesday,
+	"thursday":  time.Thursday,
+	"friday":    time.Friday,
+	"saturday":  time.Saturday,
+}
+
+var verdictsByKey = map[string]status.Status{
+	"very-negative": status.VeryNegative,
+	"negative":      status.Negative,
+	"neutral":       status.Neutral,
+	"positive":      status.Positive,
+	"very-positive": status.VeryPositive,
+	"warning":       status.Warning,
+}
+
+var treatmentsByKey = func() map[string]status.Treatment {
+	treatments := make(map[string]status.Treatment)
+	for _, t := range status.Treatments {
+		treatments[t.Key()] = t
+	}
+	return treatments
+}()
+
+// RuleDef is the definition of a rule in a rules file:
+//
+//	[[rule]]
+//	treatment = "haircut"
+//	phases = ["waning"]
+//	signs = ["capricorn", "aquarius"]
+//	weekdays = ["saturday"]
+//	lunar_days = [1, 2, 29, 30]
+//	verdict = "very-negative"
+//
+// Conditions left out match any entry.
+type RuleDef struct {
+	Treatment string   `toml:"treatment"`
+	Phases    []string `toml:"phases"`
+	Signs     []string `toml:"signs"`
+	Weekdays  []string `toml:"weekdays"`
+	LunarDays []int    `toml:"lunar_days"`
+	Verdict   string   `toml:"verdict"`
+}
+
+type rulesFile struct {
+	Rules []RuleDef `toml:"rule"`
+}
+
+// Rule overrides the built-in verdict of a treatment for the entries
+// matching all of its conditions.
+type Rule struct {
+	Treatment status.Treatment
+	Phases    []phase.Phase
+	Signs     []sign.Sign
+	Weekdays  []time.Weekday
+	LunarDays []int
+	Verdict   status.Status
+}
+
+// Set is a list of rules, in order of precedence.
+type Set struct {
+	Rules []Rule
+}
+
+func LoadFile(filename string) (*Set, error) {
+	f, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	set, err := Load(f)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", filename, err)
+	}
+	return set, nil
+}
+
+// Load reads a rules file, validating every rule in it.
+func Load(r io.Reader) (*Set, error) {
+	var f rulesFile
+	md, err := toml.NewDecoder(r).Decode(&f)
+	if err != nil {
+		return nil, err
+	}
+	if undecoded := md.Undecoded(); len(undecoded) > 0 {
+		return nil, fmt.Errorf("unknown rule settings: %v", undecoded)
+	}
+
+	return Build(f.Rules)
+}
+
+// Build validates rule definitions and turns them into a set.
+func Build(defs []RuleDef) (*Set, error) {
+	set := &Set{}
+	var errs []error
+	for i, def := range defs {
+		rule, ruleErrs := def.build()
+		for _, err := range ruleErrs {
+			errs = append(errs, fmt.Errorf("rule %d: %w", i+1, err))
+		}
+		if len(ruleErrs) == 0 {
+			set.Rules = append(set.Rules, rule)
+		}
+	}
+	return set, errors.Join(errs...)
+}
+
+func (def RuleDef) build() (rule Rule, errs []error) {
+	var ok bool
+
+	switch {
+	case def.Treatment == "":
+		errs = append(errs, errors.New("missing treatment"))
+	default:
+		rule.Treatment, ok = treatmentsByKey[strings.ToLower(def.Treatment)]
+		if !ok {
+			errs = append(errs, fmt.Errorf("unknown treatment '%s', expected one of: %s", def.Treatment, keys(treatmentsByKey)))
+		}
+	}
+
+	switch {
+	case def.Verdict == "":
+		errs = append(errs, errors.New("missing verdict"))
+	default:
+		rule.Verdict, ok = verdictsByKey[strings.ToLower(def.Verdict)]
+		if !ok {
+			errs = append(errs, fmt.Errorf("unknown verdict '%s', expected one of: %s", def.Verdict, keys(verdictsByKey)))
+		}
+	}
+
+	for _, key := range def.Phases {
+		phases, ok := phasesByKey[strings.ToLower(key)]
+		if !ok {
+			errs = append(errs, fmt.Errorf("unknown phase '%s', expected one of: %s", key, keys(phasesByKey)))
+			continue
+		}
+		rule.Phases = append(rule.Phases, phases...)
+	}
+
+	for _, key := range def.Signs {
+		s, ok := signsByKey[strings.ToLower(key)]
+		if !ok {
+			errs = append(errs, fmt.Errorf("unknown sign '%s', expected one of: %s", key, keys(signsByKey)))
+			continue
+		}
+		rule.Signs = append(rule.Signs, s)
+	}
+
+	for _, key := range def.Weekdays {
+		wd, ok := weekdaysByKey[strings.ToLower(key)]
+		if !ok {
+			errs = append(errs, fmt.Errorf("unknown weekday '%s', expected one of: %s", key, keys(weekdaysByKey)))
+			continue
+		}
+		rule.Weekdays = append(rule.Weekdays, wd)
+	}
+
+	for _, n := range def.LunarDays {
+		if n < 1 || n > 30 {
+			errs = append(errs, fmt.Errorf("lunar day %d out of range [1, 30]", n))
+			continue
+		}
+		rule.LunarDays = append(rule.LunarDays, n)
+	}
+
+	return rule, errs
+}
+
+// Matches tells whether e satisfies all the conditions of the rule.
+func (rule Rule) Matches(e status.Entry) bool {
+	return (len(rule.Phases) == 0 || contains(rule.Phases, e.Phase)) &&
+		(len(rule.Signs) == 0 || contains(rule.Signs, e.Sign)) &&
+		(len(rule.Weekdays) == 0 || contains(rule.Weekdays, e.Date.Weekday())) &&
+		(len(rule.LunarDays) == 0 || contains(rule.LunarDays, e.LunarDay))
+}
+
+// Verdict returns the verdict of the first rule for t matching e.
+// It reports false when no rule applies, so that the built-in verdict
+// is kept. A nil set holds no rules.
+func (set *Set) Verdict(t status.Treatment, e status.Entry) (status.Status, bool) {
+	if set == nil {
+		return status.Neutral, false
+	}
+	for _, rule := range set.Rules {
+		if rule.Treatment == t && rule.Matches(e) {
+			return rule.Verdict, true
+		}
+	}
+	return status.Neutral, false
+}
+
+func contains[T comparable](values []T, v T) bool {
+	for _, value := range values {
+		if value == v {
+			return true
+		}
+	}
+	return false
+}
+
+func keys[T any](m map[string]T) string {
+	var keys []string
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return strings.Join(keys, ", ")
+}
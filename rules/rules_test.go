@@ -0,0 +1,140 @@
+package rules
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mbolis/mogo/phase"
+	"github.com/mbolis/mogo/sign"
+	"github.com/mbolis/mogo/status"
+)
+
+func TestLoad(t *testing.T) {
+	set, err := Load(strings.NewReader(`
+[[rule]]
+treatment = "haircut"
+phases = ["waning", "new"]
+signs = ["Capricorn", "ophiuchus"]
+weekdays = ["saturday"]
+lunar_days = [1, 30]
+verdict = "very-negative"
+
+[[rule]]
+treatment = "face-mask"
+verdict = "positive"
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []Rule{
+		{
+			Treatment: status.Haircut,
+			Phases:    []phase.Phase{phase.Waning1, phase.Waning2, phase.Waning3, phase.New},
+			Signs:     []sign.Sign{sign.Capricorn, sign.Ophiuchus},
+			Weekdays:  []time.Weekday{time.Saturday},
+			LunarDays: []int{1, 30},
+			Verdict:   status.VeryNegative,
+		},
+		{Treatment: status.FaceMask, Verdict: status.Positive},
+	}
+	if !reflect.DeepEqual(set.Rules, want) {
+		t.Errorf("got %+v, want %+v", set.Rules, want)
+	}
+}
+
+func TestLoadErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		toml string
+		want []string
+	}{
+		{"syntax", `[[rule]`, []string{"toml: "}},
+		{"unknown setting", "[[rule]]\ntreatment = \"haircut\"\nverdict = \"neutral\"\nmoons = [\"full\"]", []string{"unknown rule settings: [rule.moons]"}},
+		{"missing", "[[rule]]\nphases = [\"full\"]", []string{"rule 1: missing treatment", "rule 1: missing verdict"}},
+		{"unknown treatment", "[[rule]]\ntreatment = \"massage\"\nverdict = \"neutral\"", []string{"rule 1: unknown treatment 'massage', expected one of: epilation, face-mask, facial-cleansing, haircut, nails-cut"}},
+		{"unknown verdict", "[[rule]]\ntreatment = \"haircut\"\nverdict = \"great\"", []string{"rule 1: unknown verdict 'great'"}},
+		{"unknown phase", "[[rule]]\ntreatment = \"haircut\"\nverdict = \"neutral\"\nphases = [\"blue\"]", []string{"rule 1: unknown phase 'blue'"}},
+		{"unknown sign", "[[rule]]\ntreatment = \"haircut\"\nverdict = \"neutral\"\nsigns = [\"cetus\"]", []string{"rule 1: unknown sign 'cetus'"}},
+		{"unknown weekday", "[[rule]]\ntreatment = \"haircut\"\nverdict = \"neutral\"\nweekdays = [\"funday\"]", []string{"rule 1: unknown weekday 'funday'"}},
+		{"lunar day", "[[rule]]\ntreatment = \"haircut\"\nverdict = \"neutral\"\nlunar_days = [0, 31]", []string{"rule 1: lunar day 0 out of range [1, 30]", "rule 1: lunar day 31 out of range [1, 30]"}},
+		{"second rule", "[[rule]]\ntreatment = \"haircut\"\nverdict = \"neutral\"\n[[rule]]\ntreatment = \"haircut\"", []string{"rule 2: missing verdict"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Load(strings.NewReader(tt.toml))
+			if err == nil {
+				t.Fatalf("got no error, want %q", tt.want)
+			}
+			lines := strings.Split(err.Error(), "\n")
+			if len(lines) != len(tt.want) {
+				t.Fatalf("got errors %q, want %q", lines, tt.want)
+			}
+			for i, want := range tt.want {
+				if !strings.HasPrefix(lines[i], want) {
+					t.Errorf("got error %q, want %q", lines[i], want)
+				}
+			}
+		})
+	}
+}
+
+// TestBuildKeepsValidRules checks that the rules without errors are kept
+// along with the errors of the others.
+func TestBuildKeepsValidRules(t *testing.T) {
+	set, err := Build([]RuleDef{
+		{Treatment: "haircut", Verdict: "oops"},
+		{Treatment: "Epilation", Verdict: "Warning"},
+	})
+	if err == nil {
+		t.Error("got no error, want the one of rule 1")
+	}
+	want := []Rule{{Treatment: status.Epilation, Verdict: status.Warning}}
+	if !reflect.DeepEqual(set.Rules, want) {
+		t.Errorf("got %+v, want %+v", set.Rules, want)
+	}
+}
+
+func TestVerdict(t *testing.T) {
+	set := &Set{Rules: []Rule{
+		{
+			Treatment: status.Haircut,
+			Phases:    []phase.Phase{phase.Full},
+			Signs:     []sign.Sign{sign.Leo, sign.Virgo},
+			Verdict:   status.VeryPositive,
+		},
+		{Treatment: status.Haircut, Weekdays: []time.Weekday{time.Saturday}, Verdict: status.Warning},
+		{Treatment: status.Haircut, LunarDays: []int{29, 30}, Verdict: status.VeryNegative},
+	}}
+
+	// 2024-03-16 is a Saturday
+	saturday := time.Date(2024, time.March, 16, 0, 0, 0, 0, time.UTC)
+	friday := saturday.AddDate(0, 0, -1)
+
+	tests := []struct {
+		name      string
+		treatment status.Treatment
+		entry     status.Entry
+		want      status.Status
+		ok        bool
+	}{
+		{"all conditions", status.Haircut, status.Entry{Date: friday, Phase: phase.Full, Sign: sign.Virgo, LunarDay: 15}, status.VeryPositive, true},
+		{"first rule wins", status.Haircut, status.Entry{Date: saturday, Phase: phase.Full, Sign: sign.Leo, LunarDay: 15}, status.VeryPositive, true},
+		{"one condition off", status.Haircut, status.Entry{Date: saturday, Phase: phase.Full, Sign: sign.Aries, LunarDay: 15}, status.Warning, true},
+		{"lunar day", status.Haircut, status.Entry{Date: friday, Phase: phase.Waning3, Sign: sign.Aries, LunarDay: 30}, status.VeryNegative, true},
+		{"no rule", status.Haircut, status.Entry{Date: friday, Phase: phase.Waning3, Sign: sign.Aries, LunarDay: 28}, status.Neutral, false},
+		{"other treatment", status.FaceMask, status.Entry{Date: saturday, Phase: phase.Full, Sign: sign.Leo, LunarDay: 30}, status.Neutral, false},
+	}
+	for _, tt := range tests {
+		got, ok := set.Verdict(tt.treatment, tt.entry)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("%s: got %v, %t, want %v, %t", tt.name, got, ok, tt.want, tt.ok)
+		}
+	}
+
+	var none *Set
+	if _, ok := none.Verdict(status.Haircut, status.Entry{}); ok {
+		t.Error("got a verdict from a nil set")
+	}
+}
@@ -0,0 +1,179 @@
+// Package search finds the times steadily growing angles, such as the
+// longitude or the elongation of the Moon, reach given values.
+package search
+
+import (
+	"math"
+	"sort"
+)
+
+// Tolerance is the precision of the crossings, in days (one second).
+const Tolerance = 1.0 / 86400
+
+// Angle is an angle in degrees [0, 360) which grows with time at a rate,
+// in degrees per day, between MinRate and MaxRate, MeanRate on average.
+type Angle struct {
+	At func(jd float64) float64
+
+	MinRate, MeanRate, MaxRate float64
+}
+
+// Crossing is the time an angle reaches the Target-th of the targets.
+type Crossing struct {
+	JD     float64
+	Target int
+}
+
+// Crossings returns the times in [from, to) the angle reaches any of the
+// targets, which must be sorted, in chronological order.
+func (a Angle) Crossings(targets []float64, from, to float64) []Crossing {
+	var crossings []Crossing
+
+	// the next target is the first one above the angle, going round
+	v := a.At(from)
+	i := sort.Search(len(targets), func(i int) bool { return targets[i] > v })
+
+	for t := from; ; {
+		i %= len(targets)
+		c, ok := a.next(targets[i], v, t, to)
+		if !ok {
+			return crossings
+		}
+		crossings = append(crossings, Crossing{c, i})
+
+		// the angle is now the target, within the tolerance
+		t, v = c, targets[i]
+		i++
+	}
+}
+
+// next finds the first time after t, when the angle is v, it reaches
+// target, if before end.
+func (a Angle) next(target, v, t, end float64) (float64, bool) {
+	// come closer without passing the target, until within a quarter
+	// turn where the difference is unambiguous: a single target just
+	// reached is a full turn away
+	d := math.Mod(target-v+720, 360)
+	if d == 0 {
+		d = 360
+	}
+	for d > 90 {
+		t += d / a.MaxRate
+		if t >= end {
+			return 0, false
+		}
+		d = -a.diff(target, t)
+		if d < 0 {
+			d += 360
+		}
+	}
+
+	// predict the crossing at the mean rate and bracket it: the angle
+	// cannot take longer than at the minimum rate
+	lo, glo := t, -d
+	hi := t + d/a.MeanRate
+	ghi := a.diff(target, hi)
+	for ghi < 0 {
+		lo, glo = hi, ghi
+		hi = lo - glo/a.MinRate
+		ghi = a.diff(target, hi)
+	}
+	if lo >= end {
+		return 0, false
+	}
+
+	c := Brent(func(t float64) float64 { return a.diff(target, t) }, lo, hi, glo, ghi, Tolerance)
+	if c >= end {
+		return 0, false
+	}
+	return c, true
+}
+
+// diff is the signed difference of the angle at t from target.
+func (a Angle) diff(target, t float64) float64 {
+	d := math.Mod(a.At(t)-target+540, 360) - 180
+	if d == -180 {
+		d = 180
+	}
+	return d
+}
+
+// Brent finds the root of the increasing function f bracketed by a and b,
+// where it is fa and fb, by Brent's method (as in Numerical Recipes,
+// zbrent). It returns the end of the final bracket, within tol of the
+// root, where f is not negative.
+func Brent(f func(float64) float64, a, b, fa, fb, tol float64) float64 {
+	if fa*fb > 0 {
+		panic("root not bracketed")
+	}
+
+	// b is the best estimate, c the other end of the bracket and a the
+	// previous estimate
+	c, fc := b, fb
+	var d, e float64
+	for {
+		if fb*fc > 0 {
+			c, fc = a, fa
+			d = b - a
+			e = d
+		}
+		if math.Abs(fc) < math.Abs(fb) {
+			a, b, c = b, c, b
+			fa, fb, fc = fb, fc, fb
+		}
+
+		tol1 := 2*epsilon*math.Abs(b) + tol/2
+		m := (c - b) / 2
+		if math.Abs(m) <= tol1 || fb == 0 {
+			break
+		}
+
+		if math.Abs(e) >= tol1 && math.Abs(fa) > math.Abs(fb) {
+			// secant, or inverse quadratic interpolation
+			s := fb / fa
+			var p, q float64
+			if a == c {
+				p = 2 * m * s
+				q = 1 - s
+			} else {
+				q = fa / fc
+				r := fb / fc
+				p = s * (2*m*q*(q-r) - (b-a)*(r-1))
+				q = (q - 1) * (r - 1) * (s - 1)
+			}
+			if p > 0 {
+				q = -q
+			}
+			p = math.Abs(p)
+
+			if 2*p < min(3*m*q-math.Abs(tol1*q), math.Abs(e*q)) {
+				e = d
+				d = p / q
+			} else {
+				d = m
+				e = d
+			}
+		} else {
+			d = m
+			e = d
+		}
+
+		a, fa = b, fb
+		switch {
+		case math.Abs(d) > tol1:
+			b += d
+		case m > 0:
+			b += tol1
+		default:
+			b -= tol1
+		}
+		fb = f(b)
+	}
+
+	if fb >= 0 {
+		return b
+	}
+	return c
+}
+
+const epsilon = 0x1p-52
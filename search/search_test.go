@@ -0,0 +1,71 @@
+package search
+
+import (
+	"math"
+	"testing"
+)
+
+func TestBrent(t *testing.T) {
+	f := func(x float64) float64 { return x*x*x - 2 }
+	root := math.Cbrt(2)
+
+	got := Brent(f, 0, 2, f(0), f(2), 1e-9)
+	if math.Abs(got-root) > 1e-9 {
+		t.Errorf("got %v, want %v", got, root)
+	}
+	if f(got) < 0 {
+		t.Errorf("f(%v) = %v, want it not negative", got, f(got))
+	}
+}
+
+func TestCrossings(t *testing.T) {
+	// a wobbling angle, 13° a day on average
+	a := Angle{
+		At: func(t float64) float64 {
+			return math.Mod(13*t+2*math.Sin(t), 360)
+		},
+		MinRate:  11,
+		MeanRate: 13,
+		MaxRate:  15,
+	}
+	targets := []float64{0, 90, 180, 270}
+
+	crossings := a.Crossings(targets, 1, 100)
+	if len(crossings) != 14 {
+		t.Fatalf("got %d crossings, want 14", len(crossings))
+	}
+
+	prev := 1.0
+	for i, c := range crossings {
+		if c.JD <= prev {
+			t.Errorf("crossing %d at %v, not after %v", i, c.JD, prev)
+		}
+		prev = c.JD
+
+		if want := (i + 1) % len(targets); c.Target != want {
+			t.Errorf("crossing %d: got target %d, want %d", i, c.Target, want)
+		}
+		if d := a.diff(targets[c.Target], c.JD); d < 0 || d > 15*Tolerance {
+			t.Errorf("crossing %d at %v: angle off by %v", i, c.JD, d)
+		}
+	}
+}
+
+func TestCrossingsSingleTarget(t *testing.T) {
+	a := Angle{
+		At:       func(t float64) float64 { return math.Mod(12*t, 360) },
+		MinRate:  11,
+		MeanRate: 12,
+		MaxRate:  15,
+	}
+
+	crossings := a.Crossings([]float64{0}, 1, 100)
+	if len(crossings) != 3 {
+		t.Fatalf("got %d crossings, want 3", len(crossings))
+	}
+	for i, c := range crossings {
+		if want := float64(30 * (i + 1)); math.Abs(c.JD-want) > Tolerance {
+			t.Errorf("crossing %d at %v, want %v", i, c.JD, want)
+		}
+	}
+}
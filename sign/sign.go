@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"time"
 
+	"github.com/mbolis/mogo/cache"
 	"github.com/mbolis/mogo/jd"
 	"github.com/mbolis/mogo/model"
 	"github.com/mbolis/mogo/position"
@@ -25,6 +26,8 @@ const (
 	Capricorn
 	Aquarius
 	Pisces
+	// Ophiuchus is only found in the IAU zodiac
+	Ophiuchus
 )
 
 func OfPosition(p position.Position) Sign {
@@ -35,10 +38,6 @@ func OfLongitude(lon float64) Sign {
 	return Sign(lon / 30)
 }
 
-func (s Sign) Prev() Sign {
-	return (s + 11) % 12
-}
-
 func (s Sign) String() string {
 	switch s {
 	case Aries:
@@ -65,6 +64,8 @@ func (s Sign) String() string {
 		return "Aquarius"
 	case Pisces:
 		return "Pisces"
+	case Ophiuchus:
+		return "Ophiuchus"
 	default:
 		panic(fmt.Sprintf("unknown sign: %d", s))
 	}
@@ -72,56 +73,61 @@ func (s Sign) String() string {
 
 type pos position.Position
 
-func (p pos) Sign() Sign {
-	return Sign(p.Longitude / 30)
-}
-
-func (p pos) Time() time.Time {
-	return jd.Time(p.JD)
+func (z Zodiac) of(p pos) Sign {
+	return z.Of(p.Longitude, p.JD)
 }
 
-var positionCache = make(map[float64]pos)
+var positionCache = cache.New[float64, pos](4096)
 
 func calcCached(d float64) pos {
-	if pos, ok := positionCache[d]; ok {
-		return pos
-	}
-
-	pos := pos(position.Calc(d, swephgo.SeMoon))
-	positionCache[d] = pos
-	return pos
+	return positionCache.GetOrCompute(d, func(d float64) pos {
+		return pos(position.Calc(d, swephgo.SeMoon))
+	})
 }
 
-func ForDay(d time.Time) (dv model.DailyValue[Sign]) {
-	d0 := time.Date(d.Year(), d.Month(), d.Day(), 0, 0, 0, 0, d.Location()).In(time.UTC)
-	pos0 := calcCached(jd.FromTime(d0))
-	pos1 := calcCached(jd.FromTime(d0.AddDate(0, 0, 1)))
+func ForDay(d time.Time) model.DailyValue[Sign] {
+	return ForDayIn(d, Tropical)
+}
 
-	dv.Curr = pos0.Sign()
-	dv.Next = pos1.Sign()
+// ForDayIn computes the signs of the day of d, as divided by z.
+func ForDayIn(d time.Time, z Zodiac) model.DailyValue[Sign] {
+	return ForDays(model.DayStart(d), model.NextDay(d), z)[0]
+}
 
-	if dv.Curr != dv.Next {
-		dv.Event = binarySearch(pos0, pos1)
-		dv.Event.Time = dv.Event.Time.In(d.Location())
+// Events returns the ingresses of the Moon from start to end, in the signs
+// as divided by z.
+func Events(start, end time.Time, z Zodiac) []model.Event[Sign] {
+	lons, signs := z.ingresses()
+
+	var events []model.Event[Sign]
+	for _, c := range z.angle().Crossings(lons, jd.FromTime(start), jd.FromTime(end)) {
+		events = append(events, model.Event[Sign]{
+			Time:  jd.Time(c.JD).In(start.Location()),
+			Value: signs[c.Target],
+		})
 	}
-
-	return
+	return events
 }
 
-func binarySearch(start, end pos) *model.Event[Sign] {
-	for {
-		mid := calcCached(start.JD + (end.JD-start.JD)/2)
-
-		if start.Sign() != mid.Sign() {
-			end = mid
-		} else {
-			start = mid
+// ForDays computes the signs of the days from start to end, which must be
+// day starts, searching the ingresses over the whole range at once.
+// Constellations as small as Scorpius may be entered and left in the same
+// day.
+func ForDays(start, end time.Time, z Zodiac) []model.DailyValue[Sign] {
+	events := Events(start, end, z)
+
+	var dvs []model.DailyValue[Sign]
+	for d := start; d.Before(end); d = model.NextDay(d) {
+		next := model.NextDay(d)
+		dv := model.DailyValue[Sign]{
+			Curr: z.of(calcCached(jd.FromTime(d))),
+			Next: z.of(calcCached(jd.FromTime(next))),
 		}
-		if end.JD-start.JD < jd.HalfMinute {
-			return &model.Event[Sign]{
-				Time:  end.Time(),
-				Value: end.Sign(),
-			}
+		for len(events) > 0 && events[0].Time.Before(next) {
+			dv.Events = append(dv.Events, events[0])
+			events = events[1:]
 		}
+		dvs = append(dvs, dv)
 	}
+	return dvs
 }
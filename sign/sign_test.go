@@ -0,0 +1,167 @@
+package sign
+
+import (
+	"encoding/csv"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/mbolis/mogo/jd"
+	"github.com/mbolis/mogo/position"
+	"github.com/mshafiee/swephgo"
+)
+
+// TestIngresses checks that the Moon is in a different sign a minute
+// before and after each ingress, in every zodiac.
+func TestIngresses(t *testing.T) {
+	start := time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC)
+	for _, z := range []Zodiac{Tropical, Constellations, IAU} {
+		ingresses := 0
+		for d := start; d.Year() == 2024; d = d.AddDate(0, 0, 1) {
+			dv := ForDayIn(d, z)
+			prev := dv.Curr
+			for _, e := range dv.Events {
+				ingresses++
+
+				before := z.of(calcCached(jd.FromTime(e.Time.Add(-time.Minute))))
+				after := z.of(calcCached(jd.FromTime(e.Time.Add(time.Minute))))
+				if before != prev || after != e.Value {
+					t.Errorf("%s, %s: %s, got %s then %s", z, d.Format(time.DateOnly), dv, before, after)
+				}
+				prev = e.Value
+			}
+			if prev != dv.Next {
+				t.Errorf("%s, %s: %s, ends in %s", z, d.Format(time.DateOnly), dv, prev)
+			}
+		}
+
+		// the Moon goes round the zodiac about 13 times a year
+		if ingresses < 13*len(zodiacSigns(z))-13 {
+			t.Errorf("%s: only %d ingresses in 2024", z, ingresses)
+		}
+	}
+}
+
+// TestEventsGolden compares the tropical ingresses of 2023 to 2025 with
+// times computed independently of the Swiss Ephemeris. tolerance covers
+// the half minute they are rounded by, the 10" of the theory they come
+// from, which the Moon crosses in about 20 seconds, and the second the
+// search of the crossings stops at.
+func TestEventsGolden(t *testing.T) {
+	const tolerance = time.Minute
+
+	f, err := os.Open("testdata/ingresses.csv")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	r := csv.NewReader(f)
+	r.Comment = '#'
+	records, err := r.ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	records = records[1:]
+
+	start := time.Date(2023, time.January, 1, 0, 0, 0, 0, time.UTC)
+	events := Events(start, start.AddDate(3, 0, 0), Tropical)
+	if len(events) != len(records) {
+		t.Fatalf("got %d ingresses, want %d", len(events), len(records))
+	}
+	for i, record := range records {
+		want, err := time.Parse("2006-01-02T15:04Z", record[0])
+		if err != nil {
+			t.Fatal(err)
+		}
+		e := events[i]
+		if e.Value.String() != record[1] {
+			t.Errorf("%s: got ingress into %s, want %s", record[0], e.Value, record[1])
+		}
+		if diff := e.Time.Sub(want).Abs(); diff > tolerance {
+			t.Errorf("%s: got ingress into %s at %s, %s off", record[0], record[1], e.Time.Format(time.RFC3339), diff)
+		}
+	}
+}
+
+// TestBoundaries checks the IAU boundaries against the dates the Sun
+// passes into each constellation, as commonly tabulated: they vary by a
+// day from year to year, which the Sun takes to move about a degree.
+func TestBoundaries(t *testing.T) {
+	entries := []struct {
+		month time.Month
+		day   int
+		sign  Sign
+	}{
+		{time.January, 20, Capricorn},
+		{time.February, 16, Aquarius},
+		{time.March, 11, Pisces},
+		{time.April, 18, Aries},
+		{time.May, 13, Taurus},
+		{time.June, 21, Gemini},
+		{time.July, 20, Cancer},
+		{time.August, 10, Leo},
+		{time.September, 16, Virgo},
+		{time.October, 30, Libra},
+		{time.November, 23, Scorpio},
+		{time.November, 29, Ophiuchus},
+		{time.December, 17, Sagittarius},
+	}
+	sunSign := func(d time.Time) Sign {
+		sun := position.CalcTime(d, swephgo.SeSun)
+		return IAU.Of(sun.Longitude, sun.JD)
+	}
+	for _, e := range entries {
+		d := time.Date(2024, e.month, e.day, 0, 0, 0, 0, time.UTC)
+		before, after := sunSign(d.AddDate(0, 0, -1)), sunSign(d.AddDate(0, 0, 2))
+		if before == e.sign || after != e.sign {
+			t.Errorf("%s: got the Sun in %s the day before and in %s the day after, want it entering %s",
+				d.Format("January 2"), before, after, e.sign)
+		}
+	}
+}
+
+func zodiacSigns(z Zodiac) []Sign {
+	if z == IAU {
+		return append([]Sign{Ophiuchus}, signs...)
+	}
+	return signs
+}
+
+var signs = []Sign{Aries, Taurus, Gemini, Cancer, Leo, Virgo, Libra, Scorpio, Sagittarius, Capricorn, Aquarius, Pisces}
+
+func TestOfLongitude(t *testing.T) {
+	for lon := 0.0; lon < 360; lon += 0.25 {
+		if got, want := OfLongitude(lon), Sign(int(lon)/30); got != want {
+			t.Errorf("OfLongitude(%f) = %s, want %s", lon, got, want)
+		}
+	}
+}
+
+func TestZodiacOf(t *testing.T) {
+	j2000 := 2451545.0
+	for _, tt := range []struct {
+		z    Zodiac
+		lon  float64
+		want Sign
+	}{
+		{Tropical, 15, Aries},
+		{Constellations, 15, Pisces},
+		{IAU, 15, Pisces},
+		{Constellations, 245, Scorpio},
+		{IAU, 245, Scorpio},
+		{IAU, 250, Ophiuchus},
+		{Constellations, 250, Scorpio},
+		{IAU, 359, Pisces},
+		{IAU, 28.8, Aries},
+	} {
+		if got := tt.z.Of(tt.lon, j2000); got != tt.want {
+			t.Errorf("%s.Of(%f) = %s, want %s", tt.z, tt.lon, got, tt.want)
+		}
+	}
+
+	// a century later the boundaries are 1.4° further along
+	if got := IAU.Of(29, j2000+36525); got != Pisces {
+		t.Errorf("IAU.Of(29) in 2100 = %s, want Pisces", got)
+	}
+}
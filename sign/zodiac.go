@@ -0,0 +1,156 @@
+package sign
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+
+	"github.com/mbolis/mogo/position"
+	"github.com/mbolis/mogo/search"
+	"github.com/mshafiee/swephgo"
+)
+
+// Zodiac is the way the ecliptic is divided among the signs.
+type Zodiac int
+
+const (
+	// Tropical divides the ecliptic in 12 equal signs from the vernal equinox
+	Tropical Zodiac = iota
+	// Constellations follows the astronomical constellations crossed by the
+	// ecliptic, Ophiuchus included in Scorpio as in biodynamic calendars
+	Constellations
+	// IAU follows the constellation boundaries set by the International
+	// Astronomical Union, Ophiuchus included
+	IAU
+)
+
+func ParseZodiac(s string) (Zodiac, error) {
+	switch strings.ToLower(s) {
+	case "tropical":
+		return Tropical, nil
+	case "constellations":
+		return Constellations, nil
+	case "iau":
+		return IAU, nil
+	default:
+		return Tropical, fmt.Errorf("unrecognized zodiac '%s', expected one of: tropical, constellations, iau", s)
+	}
+}
+
+func (z Zodiac) String() string {
+	switch z {
+	case Tropical:
+		return "tropical"
+	case Constellations:
+		return "constellations"
+	case IAU:
+		return "iau"
+	default:
+		panic(fmt.Sprintf("unknown zodiac: %d", z))
+	}
+}
+
+type boundary struct {
+	Longitude float64
+	Sign      Sign
+}
+
+// boundaries holds the ecliptic longitudes (J2000) where the ecliptic
+// enters each IAU constellation, in increasing order. The constellations
+// are those delimited by E. Delporte, Délimitation scientifique des
+// constellations (1930), as adopted by the IAU and catalogued by
+// Davenhall & Leggett, Constellation Boundary Data (1989, CDS VI/49).
+// The latitude of the Moon is neglected: the constellations it may stray
+// into off the ecliptic, as Orion or Cetus, are not considered.
+var boundaries = []boundary{
+	{28.69, Aries},
+	{53.47, Taurus},
+	{90.43, Gemini},
+	{118.26, Cancer},
+	{138.18, Leo},
+	{174.15, Virgo},
+	{218.02, Libra},
+	{241.13, Scorpio},
+	{247.64, Ophiuchus},
+	{266.27, Sagittarius},
+	{299.71, Capricorn},
+	{327.87, Aquarius},
+	{351.57, Pisces},
+}
+
+// precession is the general precession in longitude, in degrees per Julian century.
+const precession = 1.3969713
+
+// Of returns the sign of the ecliptic longitude of date lon at the Julian day d.
+func (z Zodiac) Of(lon, d float64) Sign {
+	switch z {
+	case Constellations:
+		if s := ofBoundaries(lon, d); s != Ophiuchus {
+			return s
+		}
+		return Scorpio
+	case IAU:
+		return ofBoundaries(lon, d)
+	default:
+		return OfLongitude(lon)
+	}
+}
+
+func ofBoundaries(lon, d float64) Sign {
+	lon = fixedLongitude(lon, d)
+	i := sort.Search(len(boundaries), func(i int) bool {
+		return boundaries[i].Longitude > lon
+	})
+	if i == 0 {
+		return boundaries[len(boundaries)-1].Sign
+	}
+	return boundaries[i-1].Sign
+}
+
+// fixedLongitude refers the longitude of date lon to the J2000 equinox:
+// the boundaries are fixed to the stars, the equinox moves back along them.
+func fixedLongitude(lon, d float64) float64 {
+	lon -= precession * (d - 2451545) / 36525
+	return math.Mod(lon+360, 360)
+}
+
+// ingresses returns the longitudes where the signs of z begin, as measured
+// by the angle of z, and the signs.
+func (z Zodiac) ingresses() ([]float64, []Sign) {
+	var lons []float64
+	var signs []Sign
+	switch z {
+	case Constellations, IAU:
+		for _, b := range boundaries {
+			if z == Constellations && b.Sign == Ophiuchus {
+				continue
+			}
+			lons = append(lons, b.Longitude)
+			signs = append(signs, b.Sign)
+		}
+	default:
+		for s := Aries; s <= Pisces; s++ {
+			lons = append(lons, float64(s)*30)
+			signs = append(signs, s)
+		}
+	}
+	return lons, signs
+}
+
+// angle is the longitude of the Moon where the signs of z are fixed,
+// growing by 11° to 16° a day.
+func (z Zodiac) angle() search.Angle {
+	return search.Angle{
+		At: func(d float64) float64 {
+			lon := position.Calc(d, swephgo.SeMoon).Longitude
+			if z == Tropical {
+				return lon
+			}
+			return fixedLongitude(lon, d)
+		},
+		MinRate:  11,
+		MeanRate: 360 / 27.321582,
+		MaxRate:  16,
+	}
+}
@@ -12,6 +12,14 @@ type Entry struct {
 	Time  time.Time
 	Phase phase.Phase
 	Sign  sign.Sign
+
+	// Illumination is the illuminated fraction of the Moon's disc and
+	// Age the days since the last new moon, at Time or else at Date
+	Illumination float64
+	Age          float64
+
+	// LunarDay is the number [1-30] of the lunar day
+	LunarDay int
 }
 
 type Status int
@@ -25,8 +33,17 @@ const (
 	Warning      Status = 11
 )
 
+// verdictSign is the sign the verdicts go by: Ophiuchus, which tradition
+// counts as part of Scorpio, has the verdicts of Scorpio.
+func (e Entry) verdictSign() sign.Sign {
+	if e.Sign == sign.Ophiuchus {
+		return sign.Scorpio
+	}
+	return e.Sign
+}
+
 func (e Entry) Haircut() Status {
-	switch e.Sign {
+	switch e.verdictSign() {
 	case sign.Leo, sign.Virgo:
 		return Positive
 
@@ -42,7 +59,7 @@ func (e Entry) Haircut() Status {
 }
 
 func (e Entry) NailsCut() (status Status) {
-	switch e.Sign {
+	switch e.verdictSign() {
 	case sign.Cancer, sign.Gemini, sign.Pisces:
 		status--
 	}
@@ -68,7 +85,7 @@ func (e Entry) Epilation() (status Status) {
 		status--
 	}
 
-	switch e.Sign {
+	switch e.verdictSign() {
 	case sign.Capricorn:
 		if e.Phase.IsWaning() {
 			status++
@@ -87,7 +104,7 @@ func (e Entry) FacialCleansing() (status Status) {
 	switch e.Phase {
 	case phase.Waxing1, phase.Waxing2, phase.Waxing3:
 		status--
-		if e.Sign == sign.Leo {
+		if e.verdictSign() == sign.Leo {
 			status--
 		}
 
@@ -95,7 +112,7 @@ func (e Entry) FacialCleansing() (status Status) {
 		status = VeryNegative
 
 	case phase.Waning1, phase.Waning2, phase.Waning3:
-		switch e.Sign {
+		switch e.verdictSign() {
 		case sign.Aries, sign.Capricorn:
 			status++
 		}
@@ -107,7 +124,7 @@ func (e Entry) FaceMask() (status Status) {
 	if e.Phase.IsWaxing() {
 		status++
 	}
-	if e.Sign == sign.Aries {
+	if e.verdictSign() == sign.Aries {
 		status++
 	}
 	return
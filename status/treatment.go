@@ -0,0 +1,83 @@
+package status
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Treatment is a beauty treatment which receives a verdict for each entry.
+type Treatment int
+
+const (
+	Haircut Treatment = iota
+	NailsCut
+	Epilation
+	FacialCleansing
+	FaceMask
+)
+
+// Treatments lists every treatment, in the order of the output columns.
+var Treatments = []Treatment{Haircut, NailsCut, Epilation, FacialCleansing, FaceMask}
+
+func (t Treatment) String() string {
+	switch t {
+	case Haircut:
+		return "Haircut"
+	case NailsCut:
+		return "Nails cut"
+	case Epilation:
+		return "Epilation"
+	case FacialCleansing:
+		return "Facial cleansing"
+	case FaceMask:
+		return "Face mask"
+	default:
+		panic(fmt.Sprintf("unknown treatment: %d", t))
+	}
+}
+
+// Key is the name of the treatment in rules files and JSON output.
+func (t Treatment) Key() string {
+	switch t {
+	case Haircut:
+		return "haircut"
+	case NailsCut:
+		return "nails-cut"
+	case Epilation:
+		return "epilation"
+	case FacialCleansing:
+		return "facial-cleansing"
+	case FaceMask:
+		return "face-mask"
+	default:
+		panic(fmt.Sprintf("unknown treatment: %d", t))
+	}
+}
+
+// Verdict returns the built-in verdict of e for treatment t.
+func (e Entry) Verdict(t Treatment) Status {
+	switch t {
+	case Haircut:
+		return e.Haircut()
+	case NailsCut:
+		return e.NailsCut()
+	case Epilation:
+		return e.Epilation()
+	case FacialCleansing:
+		return e.FacialCleansing()
+	case FaceMask:
+		return e.FaceMask()
+	default:
+		panic(fmt.Sprintf("unknown treatment: %d", t))
+	}
+}
+
+// ParseTreatment reads a treatment by its key or its name, in any case.
+func ParseTreatment(s string) (Treatment, error) {
+	for _, t := range Treatments {
+		if strings.EqualFold(s, t.Key()) || strings.EqualFold(s, t.String()) {
+			return t, nil
+		}
+	}
+	return -1, fmt.Errorf("unrecognized treatment '%s'", s)
+}
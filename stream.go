@@ -0,0 +1,99 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"runtime"
+	"time"
+
+	"github.com/mbolis/mogo/config"
+	"github.com/mbolis/mogo/model"
+	"golang.org/x/term"
+)
+
+// chunkDays is the number of days a worker computes at a time.
+const chunkDays = 32
+
+// progressDays is the length of the ranges whose progress is reported.
+const progressDays = 2 * 366
+
+// StreamDays computes the days from start to end in chunks, on as many
+// workers as there are CPUs, and sends them in order as soon as all the
+// previous ones are ready. Swiss Ephemeris calls are serialized by swephgo,
+// so workers overlap in all the rest: caches, searches and time zones.
+func StreamDays(cfg config.Config, start, end time.Time) <-chan Day {
+	workers := runtime.GOMAXPROCS(0)
+
+	// chunks are queued in order, each with its own result channel: the
+	// queue bounds the days computed ahead of the slowest chunk
+	chunks := make(chan chan []Day, workers)
+	go func() {
+		defer close(chunks)
+		for d := start; d.Before(end); d = model.Midnight(d.Year(), d.Month(), d.Day()+chunkDays, d.Location()) {
+			chunkEnd := model.Midnight(d.Year(), d.Month(), d.Day()+chunkDays, d.Location())
+			if chunkEnd.After(end) {
+				chunkEnd = end
+			}
+
+			result := make(chan []Day, 1)
+			chunks <- result
+			go func(start, end time.Time) {
+				result <- ComputeDays(cfg, start, end)
+			}(d, chunkEnd)
+		}
+	}()
+
+	progress := newProgress(os.Stderr, start, end)
+	days := make(chan Day, chunkDays)
+	go func() {
+		defer close(days)
+		defer progress.done()
+		for result := range chunks {
+			chunk := <-result
+			for _, d := range chunk {
+				days <- d
+			}
+			progress.add(len(chunk))
+		}
+	}()
+	return days
+}
+
+// CollectDays computes the days from start to end in parallel, for the
+// formats which cannot be streamed.
+func CollectDays(cfg config.Config, start, end time.Time) []Day {
+	var days []Day
+	for d := range StreamDays(cfg, start, end) {
+		days = append(days, d)
+	}
+	return days
+}
+
+// progress reports the days computed on a terminal, for long ranges only.
+type progress struct {
+	w     io.Writer
+	total int
+	count int
+}
+
+func newProgress(f *os.File, start, end time.Time) *progress {
+	total := int(end.Sub(start).Hours()/24 + 0.5)
+	if total < progressDays || !term.IsTerminal(int(f.Fd())) {
+		return &progress{}
+	}
+	return &progress{w: f, total: total}
+}
+
+func (p *progress) add(n int) {
+	p.count += n
+	if p.w != nil {
+		fmt.Fprintf(p.w, "\r%d/%d days (%d%%)", p.count, p.total, p.count*100/p.total)
+	}
+}
+
+func (p *progress) done() {
+	if p.w != nil {
+		fmt.Fprint(p.w, "\r\033[K")
+	}
+}
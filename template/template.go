@@ -2,7 +2,7 @@ package template
 
 import (
 	"bytes"
-	_ "embed"
+	"embed"
 )
 
 //go:embed template.ods
@@ -11,6 +11,12 @@ var ods []byte
 //go:embed template.xlsx
 var xlsx []byte
 
+//go:embed template.html
+var html string
+
+//go:embed icons/*.svg
+var icons embed.FS
+
 func ODS() (*bytes.Reader, int64) {
 	return bytes.NewReader(ods), int64(len(ods))
 }
@@ -18,3 +24,11 @@ func ODS() (*bytes.Reader, int64) {
 func XLSX() *bytes.Reader {
 	return bytes.NewReader(xlsx)
 }
+
+func HTML() string {
+	return html
+}
+
+func Icon(name string) ([]byte, error) {
+	return icons.ReadFile("icons/" + name + ".svg")
+}
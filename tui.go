@@ -0,0 +1,286 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mattn/go-runewidth"
+	"github.com/mbolis/mogo/config"
+	"github.com/mbolis/mogo/model"
+	"golang.org/x/term"
+)
+
+type key int
+
+const (
+	keyNone key = iota
+	keyUp
+	keyDown
+	keyLeft
+	keyRight
+	keyPrevMonth
+	keyNextMonth
+	keyToday
+	keyQuit
+)
+
+// tui is an interactive month calendar: the selected day's events
+// are listed below the grid with their exact times.
+type tui struct {
+	cfg      config.Config
+	selected time.Time
+	months   map[time.Time][]Day
+	out      *bufio.Writer
+}
+
+func RunTUI(cfg config.Config) {
+	fd := int(os.Stdin.Fd())
+	state, err := term.MakeRaw(fd)
+	if err != nil {
+		panic(err)
+	}
+	defer term.Restore(fd, state)
+
+	start, _ := cfg.Range()
+	ui := &tui{
+		cfg:      cfg,
+		selected: start,
+		months:   make(map[time.Time][]Day),
+		out:      bufio.NewWriter(os.Stdout),
+	}
+	today := ui.today()
+	if y, m, _ := cfg.Calendar.Date(today); y == cfg.Year && (cfg.Month == 0 || m == cfg.Month) {
+		ui.selected = today
+	}
+
+	// switch to the alternate screen and hide the cursor
+	ui.out.WriteString("\x1b[?1049h\x1b[?25l")
+	defer func() {
+		ui.out.WriteString("\x1b[?25h\x1b[?1049l")
+		ui.out.Flush()
+	}()
+
+	in := bufio.NewReader(os.Stdin)
+	for {
+		ui.render()
+
+		switch readKey(in) {
+		case keyUp:
+			ui.selected = addDays(ui.selected, -7)
+		case keyDown:
+			ui.selected = addDays(ui.selected, 7)
+		case keyLeft:
+			ui.selected = addDays(ui.selected, -1)
+		case keyRight:
+			ui.selected = addDays(ui.selected, 1)
+		case keyPrevMonth:
+			ui.selected = ui.addMonths(ui.selected, -1)
+		case keyNextMonth:
+			ui.selected = ui.addMonths(ui.selected, 1)
+		case keyToday:
+			ui.selected = ui.today()
+		case keyQuit:
+			return
+		}
+	}
+}
+
+func readKey(in *bufio.Reader) key {
+	b, err := in.ReadByte()
+	if err != nil {
+		return keyQuit
+	}
+
+	switch b {
+	case 'q', 'Q', 3: // ctrl-c
+		return keyQuit
+	case 'k':
+		return keyUp
+	case 'j':
+		return keyDown
+	case 'h':
+		return keyLeft
+	case 'l':
+		return keyRight
+	case 'p', '<':
+		return keyPrevMonth
+	case 'n', '>':
+		return keyNextMonth
+	case 't', 'T':
+		return keyToday
+	case 0x1b:
+		if in.Buffered() == 0 {
+			return keyQuit
+		}
+		seq := make([]byte, 0, 3)
+		for in.Buffered() > 0 && len(seq) < cap(seq) {
+			b, _ := in.ReadByte()
+			seq = append(seq, b)
+			if b >= 'A' && b <= 'Z' || b == '~' {
+				break
+			}
+		}
+		switch string(seq) {
+		case "[A", "OA":
+			return keyUp
+		case "[B", "OB":
+			return keyDown
+		case "[D", "OD":
+			return keyLeft
+		case "[C", "OC":
+			return keyRight
+		case "[5~":
+			return keyPrevMonth
+		case "[6~":
+			return keyNextMonth
+		}
+	}
+	return keyNone
+}
+
+func (ui *tui) today() time.Time {
+	return model.DayStart(time.Now().In(ui.cfg.TZ))
+}
+
+func addDays(d time.Time, n int) time.Time {
+	return model.Midnight(d.Year(), d.Month(), d.Day()+n, d.Location())
+}
+
+func (ui *tui) addMonths(d time.Time, n int) time.Time {
+	c := ui.cfg.Calendar
+	y, m, day := c.Date(d)
+	_, _, last := c.Date(c.Midnight(y, m+time.Month(n)+1, 0, d.Location()))
+	return c.Midnight(y, m+time.Month(n), min(day, last), d.Location())
+}
+
+func (ui *tui) month(d time.Time) []Day {
+	c := ui.cfg.Calendar
+	y, m, _ := c.Date(d)
+	first := c.Midnight(y, m, 1, d.Location())
+	days, ok := ui.months[first]
+	if !ok {
+		days = ComputeDays(ui.cfg, first, c.Midnight(y, m+1, 1, d.Location()))
+		ui.months[first] = days
+	}
+	return days
+}
+
+const tuiCellWidth = 10
+
+func (ui *tui) render() {
+	w := ui.out
+	w.WriteString("\x1b[H\x1b[2J")
+
+	days := ui.month(ui.selected)
+	today := ui.today()
+
+	y, m, _ := ui.cfg.Calendar.Date(ui.selected)
+	title := T("month."+m.String()[:3]) + " " + strconv.Itoa(y)
+	w.WriteString(ansiBold + title + ansiReset + "\r\n\r\n")
+
+	for i := 0; i < 7; i++ {
+		weekday := time.Weekday((i + 1) % 7)
+		name := T("weekday." + weekday.String()[:3])
+		w.WriteString(padRight(name, tuiCellWidth))
+	}
+	w.WriteString("\r\n")
+
+	// weeks start on Monday
+	offset := (int(days[0].Time.Weekday()) + 6) % 7
+	w.WriteString(strings.Repeat(" ", offset*tuiCellWidth))
+
+	for i, d := range days {
+		phaseIcon := ui.cfg.Icons.Phase(d.Phase.Value())
+		signIcon := ui.cfg.Icons.Sign(d.Sign.Value())
+		_, _, day := ui.cfg.Calendar.Date(d.Time)
+		cell := fmt.Sprintf("%2d %s%s", day, phaseIcon, signIcon)
+		cell = padRight(cell, tuiCellWidth-1)
+
+		switch {
+		case sameDay(d.Time, ui.selected):
+			w.WriteString("\x1b[7m" + cell + ansiReset + " ")
+		case sameDay(d.Time, today):
+			w.WriteString("\x1b[4m" + cell + ansiReset + " ")
+		default:
+			w.WriteString(cell + " ")
+		}
+
+		if (offset+i)%7 == 6 {
+			w.WriteString("\r\n")
+		}
+	}
+	w.WriteString("\r\n\r\n")
+
+	for _, d := range days {
+		if sameDay(d.Time, ui.selected) {
+			ui.renderDay(d)
+		}
+	}
+
+	w.WriteString("\r\n" + ansiFaint +
+		"←↓↑→/hjkl: day  p/n PgUp/PgDn: month  t: today  q: quit" + ansiReset)
+	w.Flush()
+}
+
+func (ui *tui) renderDay(d Day) {
+	w := ui.out
+
+	_, _, n := ui.cfg.Calendar.Date(d.Time)
+	day := T("weekday."+d.Time.Format("Mon")) + " " + strconv.Itoa(n)
+	w.WriteString(ansiBold + day + ansiReset + "\r\n")
+
+	if len(d.Sky) > 0 {
+		w.WriteString(" ")
+		for _, e := range d.Sky {
+			fmt.Fprintf(w, " %s %s", T(e.Value.String()), clock(e.Time, ui.cfg.Precision.Layout()))
+		}
+		w.WriteString("\r\n")
+	}
+
+	fmt.Fprintf(w, "  %s %d", T("Lunar day"), d.LunarDay.Start)
+	for _, c := range d.LunarDay.Changes {
+		fmt.Fprintf(w, " → %d %s", c.Value, clock(c.Time, ui.cfg.Precision.Layout()))
+	}
+	w.WriteString("\r\n")
+
+	if ui.cfg.ElementColumn {
+		e := d.Element
+		fmt.Fprintf(w, "  %s %s %s", T("Element"), ui.cfg.Icons.Element(e.Curr), T("organ."+e.Curr.Organ()))
+		for _, c := range e.Events {
+			fmt.Fprintf(w, " → %s %s %s", ui.cfg.Icons.Element(c.Value), T("organ."+c.Value.Organ()), clock(c.Time, ui.cfg.Precision.Layout()))
+		}
+		w.WriteString("\r\n")
+	}
+
+	header := Header(ui.cfg)
+	for _, r := range d.Rows(ui.cfg) {
+		time := "--:--:--"
+		if !r.Time.IsZero() {
+			time = clock(r.Time, "15:04:05")
+		}
+
+		phaseIcon, phaseName := r.PhaseText()
+		signIcon, signName := r.SignText()
+		fmt.Fprintf(w, "  %s  %s %s  %s %s\r\n", time, phaseIcon, phaseName, signIcon, signName)
+
+		for i, s := range r.Statuses() {
+			icon := ui.cfg.Icons.Status(s)
+			if icon == "" {
+				continue
+			}
+			fmt.Fprintf(w, "    %s%s%s %s\r\n", statusColor(s), padRight(icon, 4), ansiReset, header[statusColumn+i])
+		}
+	}
+}
+
+func sameDay(a, b time.Time) bool {
+	return a.Year() == b.Year() && a.YearDay() == b.YearDay()
+}
+
+func padRight(s string, width int) string {
+	return s + strings.Repeat(" ", max(0, width-runewidth.StringWidth(s)))
+}